@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold"
+)
+
+var addEntityCmd = &cobra.Command{
+	Use:   "add-entity [service-name] [Entity]",
+	Short: "Add a CRUD entity to a service's proto",
+	Long: `Add a CRUD entity to proto/<service>/<service>.proto:
+- The entity message (id, your fields, created_at/updated_at, created_by/updated_by)
+- An enum for every enum(...) field, e.g. EnrollmentStatus
+- Create/Get/Update/Delete/List request and response messages
+- The five rpcs in the service
+
+Fields are given as --field name:type, name:optional:type or name:enum(A,B).
+Types: string, bytes, bool, int32, int64, uint32, uint64, float, double,
+timestamp. Enum values share the proto package scope, so a value already
+used by another enum of the service is refused.
+
+--dry-run and --diff preview the proto change without writing it.
+
+Example:
+  grpc-gen add-entity school Enrollment \
+    --field name:string --field 'status:enum(ACTIVE,DROPPED)' --field score:optional:int32`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serviceName := scaffold.NormalizeServiceName(args[0])
+		specs, _ := cmd.Flags().GetStringArray("field")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		diff, _ := cmd.Flags().GetBool("diff")
+		preview := scaffold.Preview{DryRun: dryRun, Diff: diff}
+
+		entity := scaffold.Entity{Name: args[1]}
+		for _, spec := range specs {
+			field, err := scaffold.ParseEntityField(spec)
+			if err != nil {
+				return err
+			}
+			entity.Fields = append(entity.Fields, field)
+		}
+
+		fmt.Printf("📝 Adding entity %s to service %s\n\n", entity.Name, serviceName)
+
+		if err := scaffold.AddEntity(serviceName, entity, preview); err != nil {
+			return fmt.Errorf("failed to add entity: %w", err)
+		}
+
+		if preview.Enabled() {
+			fmt.Println("\nNothing written (preview)")
+			return nil
+		}
+
+		fmt.Printf("\n✅ Entity added successfully!\n\n")
+		fmt.Println("Next steps:")
+		fmt.Printf("  1. make gen-%s                     # Regenerate the service\n", serviceName)
+		fmt.Printf("  2. grpc-gen gen-migration %s       # Create the table\n", serviceName)
+		fmt.Printf("  3. go build ./src/service/%s\n", serviceName)
+		fmt.Println()
+
+		return nil
+	},
+}
+
+func init() {
+	// StringArray, not StringSlice: enum(A,B) contains commas
+	addEntityCmd.Flags().StringArray("field", nil, "Entity field as name:type, name:optional:type or name:enum(A,B) (repeatable)")
+	addEntityCmd.Flags().Bool("dry-run", false, "List the files that would be modified without writing them")
+	addEntityCmd.Flags().Bool("diff", false, "Show a unified diff of the changes without writing them")
+}
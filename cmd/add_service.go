@@ -1,45 +1,118 @@
 package cmd
 
 import (
+	"cmp"
 	"fmt"
-	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/thailyhcmut/grpc-gen/internal/scaffold"
 )
 
 var addServiceCmd = &cobra.Command{
-	Use:   "add-service [service-name] [port]",
+	Use:   "add-service [service-name] [port|auto]",
 	Short: "Add a new service to the project",
 	Long: `Add a new gRPC service to your project:
 - Creates proto definition
-- Updates Makefile with new targets
-- Ready for entity definitions
+- Records the service in grpc-gen.yaml (port, TLS and migrate mode)
+- Regenerates the Makefile from grpc-gen.yaml
+- Declares the entities given with --entity, or an example entity
+
+Service names are lower-case words joined by underscores (user, post_type);
+User and post-type are accepted and stored as user and post_type. The port
+must not be used by another service: "auto" (or --port auto) picks the lowest
+free port from 50051.
+
+-i asks for the name, port, TLS and migrate mode and the entities, with the
+arguments and flags as defaults.
+
+--dry-run lists the files that would be created or modified and --diff shows
+a unified diff against disk; neither writes anything.
 
 Example:
   grpc-gen add-service user 50051
-  grpc-gen add-service order 50052`,
-	Args: cobra.ExactArgs(2),
+  grpc-gen add-service order --port auto --diff
+  grpc-gen add-service admin 50053 --tls mtls --migrate check
+  grpc-gen add-service school 50054 --entity 'Enrollment name:string status:enum(ENROLLED,DROPPED)'
+  grpc-gen add-service -i`,
+	Args: cobra.RangeArgs(0, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		serviceName := args[0]
-		portStr := args[1]
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		diff, _ := cmd.Flags().GetBool("diff")
+		preview := scaffold.Preview{DryRun: dryRun, Diff: diff}
+		tlsMode, _ := cmd.Flags().GetString("tls")
+		migrate, _ := cmd.Flags().GetString("migrate")
+		specs, _ := cmd.Flags().GetStringArray("entity")
+		interactive, _ := cmd.Flags().GetBool("interactive")
+
+		portStr, _ := cmd.Flags().GetString("port")
+		var serviceName string
+		if len(args) > 0 {
+			serviceName = scaffold.NormalizeServiceName(args[0])
+		}
+		if len(args) > 1 {
+			if cmd.Flags().Changed("port") {
+				return fmt.Errorf("give the port as argument or with --port, not both")
+			}
+			portStr = args[1]
+		}
+		if !interactive && (serviceName == "" || portStr == "") {
+			return fmt.Errorf("requires a service name and a port (or --port auto, or -i)")
+		}
+
+		project, err := scaffold.LoadProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		entities, err := parseEntities(serviceName, specs)
+		if err != nil {
+			return err
+		}
+
+		var port int
+		if interactive {
+			p := newPrompter()
+			serviceName = p.serviceName("Service name", serviceName, project, false)
+			def := project.NextPort()
+			if port, err := project.ParsePort(portStr); err == nil {
+				def = port
+			}
+			port = p.port("Port (or auto)", def, project.ParsePort)
 
-		// Validate port
-		port, err := strconv.Atoi(portStr)
-		if err != nil || port < 1024 || port > 65535 {
-			return fmt.Errorf("invalid port number: %s (must be between 1024-65535)", portStr)
+			// Keep grpc-gen.yaml free of overrides equal to the defaults
+			if tlsMode = p.choose("TLS mode", scaffold.TLSModes, cmp.Or(tlsMode, project.TLS)); tlsMode == project.TLS {
+				tlsMode = ""
+			}
+			if migrate = p.choose("Migrations on startup", scaffold.MigrateModes, cmp.Or(migrate, scaffold.DefaultMigrateMode)); migrate == scaffold.DefaultMigrateMode {
+				migrate = ""
+			}
+			entities = p.entities(serviceName, entities)
+			fmt.Println()
+		} else if port, err = project.ParsePort(portStr); err != nil {
+			return err
 		}
 
 		fmt.Printf("📝 Adding service: %s on port %d\n\n", serviceName, port)
 
 		// Add service to project
-		if err := scaffold.AddService(serviceName, port); err != nil {
+		if err := scaffold.AddService(scaffold.ServiceConfig{
+			Name:    serviceName,
+			Port:    port,
+			TLS:     tlsMode,
+			Migrate: migrate,
+		}, entities, preview); err != nil {
 			return fmt.Errorf("failed to add service: %w", err)
 		}
 
+		if preview.Enabled() {
+			fmt.Println("\nNothing written (preview)")
+			return nil
+		}
+
 		fmt.Printf("\n✅ Service added successfully!\n\n")
 		fmt.Println("Next steps:")
-		fmt.Printf("  1. Edit proto/%s/%s.proto to define your entities\n", serviceName, serviceName)
+		fmt.Printf("  1. Edit proto/%s/%s.proto (or grpc-gen add-entity) to define your entities\n", serviceName, serviceName)
 		fmt.Printf("  2. ./generate-certs.sh %s          # Generate TLS certificates\n", serviceName)
 		fmt.Printf("  3. make gen-%s\n", serviceName)
 		fmt.Printf("  4. go build ./src/service/%s\n", serviceName)
@@ -48,3 +121,14 @@ Example:
 		return nil
 	},
 }
+
+func init() {
+	addServiceCmd.Flags().String("port", "", "Port of the service, or auto for the lowest free port from 50051 (instead of the port argument)")
+	addServiceCmd.Flags().BoolP("interactive", "i", false, "Ask for every setting (the arguments and flags give the defaults)")
+	// StringArray, not StringSlice: enum(A,B) contains commas
+	addServiceCmd.Flags().StringArray("entity", nil, "Entity as 'Name field...', fields as in add-entity (repeatable)")
+	addServiceCmd.Flags().Bool("dry-run", false, "List the files that would be created or modified without writing them")
+	addServiceCmd.Flags().Bool("diff", false, "Show a unified diff of the changes without writing them")
+	addServiceCmd.Flags().String("tls", "", "TLS mode of this service: "+strings.Join(scaffold.TLSModes, ", ")+" (default: the project's)")
+	addServiceCmd.Flags().String("migrate", "", "DB_MIGRATE of this service: "+strings.Join(scaffold.MigrateModes, ", ")+" (default: "+scaffold.DefaultMigrateMode+")")
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the tools and the project for common problems",
+	Long: `Check what make gen-<service> and the services rely on, and print a fix
+for every problem:
+
+  - protoc, protoc-gen-go and protoc-gen-go-grpc are installed, recent enough
+    and not newer than the runtime required in go.mod
+  - grpc-gen.yaml is valid, its module matches go.mod and the go_package of
+    the protos, and no two services share a port
+  - go.mod requires the modules of the generated code
+  - the Makefile is the one generated from grpc-gen.yaml
+  - every service has generated code, env files and, unless its TLS mode is
+    insecure, valid certificates in src/service/<name>/certs
+
+Run it from the project root (outside a project only the tools are checked).
+It exits with status 1 when a check fails, and with --strict also on warnings,
+for CI.
+
+Example:
+  grpc-gen doctor
+  grpc-gen doctor --strict`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		strict, _ := cmd.Flags().GetBool("strict")
+
+		var failed, warned int
+		group := ""
+		for _, c := range scaffold.Doctor() {
+			if c.Group != group {
+				group = c.Group
+				fmt.Printf("\n%s\n", group)
+			}
+
+			mark := "✓"
+			switch c.Status {
+			case scaffold.CheckWarn:
+				mark = "!"
+				warned++
+			case scaffold.CheckFail:
+				mark = "✗"
+				failed++
+			}
+			fmt.Printf("  %s %-18s %s\n", mark, c.Name, c.Detail)
+			if c.Fix != "" {
+				fmt.Printf("      fix: %s\n", c.Fix)
+			}
+		}
+
+		fmt.Println()
+		if failed > 0 || (strict && warned > 0) {
+			return fmt.Errorf("%d check(s) failed, %d warning(s)", failed, warned)
+		}
+		if warned > 0 {
+			fmt.Printf("✅ No problems found (%d warning(s))\n", warned)
+			return nil
+		}
+		fmt.Println("✅ No problems found")
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().Bool("strict", false, "Exit with status 1 on warnings too")
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/thailyhcmut/grpc-gen/internal/migration"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold"
+)
+
+var genMigrationCmd = &cobra.Command{
+	Use:   "gen-migration [service-name]",
+	Short: "Generate SQL migrations from a service's entity messages",
+	Long: `Generate SQL schema migrations for a service:
+- Reads proto/<service>/<service>.proto
+- First run: one CREATE TABLE per CRUD entity (id, entity fields, created_at/updated_at/created_by/updated_by)
+- Later runs: ALTER statements for the changes since the last run (tracked in migrations/<service>/.schema.json)
+- Writes numbered up/down files under migrations/<service>/
+
+Destructive changes (dropped tables/columns, NOT NULL, removed enum values, type changes)
+are refused unless --allow-destructive is given.
+
+Example:
+  grpc-gen gen-migration user
+  grpc-gen gen-migration user --allow-destructive
+  make migrate-user`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serviceName := scaffold.NormalizeServiceName(args[0])
+		allowDestructive, _ := cmd.Flags().GetBool("allow-destructive")
+
+		if _, err := os.Stat(filepath.Join("proto", serviceName, serviceName+".proto")); err != nil {
+			return fmt.Errorf("service %s not found (proto/%s/%s.proto is missing)", serviceName, serviceName, serviceName)
+		}
+
+		project, err := scaffold.LoadProjectConfig()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("🗄️  Generating %s migrations for: %s\n\n", project.Dialect, serviceName)
+
+		result, err := migration.Generate(migration.Options{
+			Service:          serviceName,
+			ModulePath:       project.Module,
+			Dialect:          project.Dialect,
+			AllowDestructive: allowDestructive,
+		})
+		if result != nil {
+			for _, change := range result.Changes {
+				marker := "+"
+				if change.Destructive {
+					marker = "⚠️ "
+				}
+				fmt.Printf("  %s %s\n", marker, change)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to generate migration: %w", err)
+		}
+
+		if len(result.Changes) == 0 {
+			fmt.Println("  Schema is up to date, nothing to generate")
+			fmt.Println()
+			return nil
+		}
+
+		fmt.Println()
+		for _, file := range result.Files {
+			fmt.Printf("  ✓ Wrote %s\n", file)
+		}
+		fmt.Println()
+
+		return nil
+	},
+}
+
+func init() {
+	genMigrationCmd.Flags().Bool("allow-destructive", false, "Generate destructive changes (drops, NOT NULL, removed enum values)")
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold"
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate [service-name...]",
+	Short: "Generate service skeletons from their protos",
+	Long: `Generate the code of services from proto/<service>/<service>.proto with the
+generator built into grpc-gen (every service of grpc-gen.yaml when none is
+named). Port, TLS, migrate and trace mode come from grpc-gen.yaml.
+
+Files with the "Code generated" header are rewritten; hooks, env credentials
+and other user files are only created once. --force overwrites files without
+the header.
+
+Templates are read from the project's template/ directory when it has them and
+from grpc-gen otherwise, so upgrading grpc-gen upgrades the generated code.
+--eject-templates copies the built-in templates to template/ for customizing.
+
+Run protoc first (make gen-<service> does both).
+
+Example:
+  grpc-gen generate user
+  grpc-gen generate --diff
+  grpc-gen generate --eject-templates`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		diff, _ := cmd.Flags().GetBool("diff")
+		eject, _ := cmd.Flags().GetBool("eject-templates")
+
+		if eject {
+			written, err := scaffold.EjectTemplates()
+			if err != nil {
+				return err
+			}
+			for _, path := range written {
+				fmt.Printf("  ✓ Created %s\n", path)
+			}
+			fmt.Printf("\n✅ %d template(s) ejected; delete one to go back to the built-in version\n", len(written))
+			return nil
+		}
+
+		for i := range args {
+			args[i] = scaffold.NormalizeServiceName(args[i])
+		}
+		return scaffold.GenerateServices(args, force, scaffold.Preview{DryRun: dryRun, Diff: diff})
+	},
+}
+
+func init() {
+	generateCmd.Flags().Bool("force", false, "Overwrite files without the generated header and remove files of the old layout")
+	generateCmd.Flags().Bool("dry-run", false, "List the files that would be created, modified or deleted without writing them")
+	generateCmd.Flags().Bool("diff", false, "Show a unified diff of the changes without writing them")
+	generateCmd.Flags().Bool("eject-templates", false, "Copy the built-in templates missing from template/ and exit")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/thailyhcmut/grpc-gen/internal/scaffold"
@@ -17,7 +18,18 @@ var initCmd = &cobra.Command{
 - Proto definitions
 - Common utilities (logger, database)
 - Makefile for building
-- Docker configuration`,
+- Docker configuration
+- Optionally a first service with its entities (--service, --entity)
+
+-i asks for every setting, with the flags as defaults.
+
+Example:
+  grpc-gen init myproject -m github.com/me/myproject
+  grpc-gen init myproject --db postgres
+  grpc-gen init myproject --tls tls --trace console
+  grpc-gen init myproject --service school --port 50051 \
+    --entity 'Enrollment name:string status:enum(ENROLLED,DROPPED) score:optional:int32'
+  grpc-gen init myproject -i`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		projectName := args[0]
@@ -27,6 +39,55 @@ var initCmd = &cobra.Command{
 		if modulePath == "" {
 			modulePath = projectName
 		}
+		dialect, _ := cmd.Flags().GetString("db")
+		tlsMode, _ := cmd.Flags().GetString("tls")
+		traceMode, _ := cmd.Flags().GetString("trace")
+		serviceName, _ := cmd.Flags().GetString("service")
+		port, _ := cmd.Flags().GetInt("port")
+		specs, _ := cmd.Flags().GetStringArray("entity")
+		interactive, _ := cmd.Flags().GetBool("interactive")
+
+		serviceName = scaffold.NormalizeServiceName(serviceName)
+		if serviceName == "" && len(specs) > 0 {
+			return fmt.Errorf("--entity needs --service")
+		}
+		entities, err := parseEntities(serviceName, specs)
+		if err != nil {
+			return err
+		}
+
+		if interactive {
+			p := newPrompter()
+			modulePath = p.ask("Go module path", modulePath)
+			dialect = p.choose("Database", scaffold.Dialects, dialect)
+			if p.confirm("Enable mTLS (clients must present a certificate)?", tlsMode == "mtls") {
+				tlsMode = "mtls"
+			} else {
+				if tlsMode == "mtls" {
+					tlsMode = "tls"
+				}
+				tlsMode = p.choose("TLS mode", []string{"tls", "insecure"}, tlsMode)
+			}
+			traceMode = p.choose("Request tracing", scaffold.TraceModes, traceMode)
+			serviceName = p.serviceName("First service (empty to skip)", serviceName, &scaffold.ProjectConfig{}, true)
+			if serviceName != "" {
+				port = p.port("Port", port, (&scaffold.ProjectConfig{}).ParsePort)
+				entities = p.entities(serviceName, entities)
+			}
+			fmt.Println()
+		}
+
+		cfg := scaffold.ProjectConfig{Module: modulePath, Dialect: dialect, TLS: tlsMode, Trace: traceMode}
+		service := scaffold.ServiceConfig{Name: serviceName, Port: port}
+		if serviceName != "" {
+			cfg.Services = []scaffold.ServiceConfig{service}
+			if err := scaffold.ValidateEntities(serviceName, entities); err != nil {
+				return err
+			}
+		}
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
 
 		// Create project directory
 		if err := os.MkdirAll(projectName, 0755); err != nil {
@@ -42,17 +103,29 @@ var initCmd = &cobra.Command{
 		fmt.Printf("📦 Creating project in: %s\n\n", absPath)
 
 		// Scaffold the project
-		if err := scaffold.CreateProject(projectName, modulePath); err != nil {
+		if err := scaffold.CreateProject(projectName, cfg); err != nil {
 			return fmt.Errorf("failed to scaffold project: %w", err)
 		}
 
+		if serviceName != "" {
+			fmt.Printf("\n📝 Adding service: %s on port %d\n", serviceName, port)
+			if err := scaffold.AddService(service, entities, scaffold.Preview{}); err != nil {
+				return fmt.Errorf("failed to add service: %w", err)
+			}
+		}
+
 		fmt.Printf("\n✅ Project created successfully!\n\n")
 		fmt.Println("Next steps:")
 		fmt.Printf("  cd %s\n", projectName)
-		fmt.Println("  grpc-gen add-service user 50051")
-		fmt.Println("  ./generate-certs.sh user          # Generate TLS certificates")
-		fmt.Println("  make gen-user")
-		fmt.Println("  go build ./src/service/user")
+		if serviceName == "" {
+			serviceName = "user"
+			fmt.Println("  grpc-gen add-service user 50051")
+		}
+		if tlsMode != "insecure" {
+			fmt.Printf("  ./generate-certs.sh %s          # Generate TLS certificates\n", serviceName)
+		}
+		fmt.Printf("  make gen-%s\n", serviceName)
+		fmt.Printf("  go build ./src/service/%s\n", serviceName)
 		fmt.Println()
 
 		return nil
@@ -60,5 +133,13 @@ var initCmd = &cobra.Command{
 }
 
 func init() {
+	initCmd.Flags().BoolP("interactive", "i", false, "Ask for every setting (the flags give the defaults)")
 	initCmd.Flags().StringP("module", "m", "", "Go module path (default: project-name)")
+	initCmd.Flags().String("db", scaffold.DefaultDialect, "Database: "+strings.Join(scaffold.Dialects, " or "))
+	initCmd.Flags().String("tls", scaffold.DefaultTLSMode, "Default TLS mode of services: "+strings.Join(scaffold.TLSModes, ", "))
+	initCmd.Flags().String("trace", scaffold.DefaultTraceMode, "Request tracing: "+strings.Join(scaffold.TraceModes, ", "))
+	initCmd.Flags().String("service", "", "Add a first service with this name")
+	initCmd.Flags().Int("port", 50051, "Port of the first service")
+	// StringArray, not StringSlice: enum(A,B) contains commas
+	initCmd.Flags().StringArray("entity", nil, "Entity of the first service as 'Name field...', fields as in add-entity (repeatable)")
 }
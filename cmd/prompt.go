@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold"
+)
+
+// prompter asks the questions of the interactive (-i) wizards. Every answer
+// has a default, the value of the matching flag, taken on an empty line.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPrompter() *prompter {
+	return &prompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+}
+
+// line prints label and returns the trimmed answer; EOF counts as empty
+func (p *prompter) line(label string) string {
+	fmt.Fprint(p.out, label)
+	answer, _ := p.in.ReadString('\n')
+	return strings.TrimSpace(answer)
+}
+
+// ask returns the answer, or def when it is empty
+func (p *prompter) ask(label, def string) string {
+	if def != "" {
+		label += " [" + def + "]"
+	}
+	if answer := p.line(label + ": "); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// choose asks until the answer is one of choices
+func (p *prompter) choose(label string, choices []string, def string) string {
+	for {
+		answer := p.ask(label+" ("+strings.Join(choices, ", ")+")", def)
+		for _, c := range choices {
+			if answer == c {
+				return answer
+			}
+		}
+		fmt.Fprintf(p.out, "  %q is not one of %s\n", answer, strings.Join(choices, ", "))
+	}
+}
+
+// confirm asks a yes/no question
+func (p *prompter) confirm(label string, def bool) bool {
+	hint := " [y/N]: "
+	if def {
+		hint = " [Y/n]: "
+	}
+	for {
+		switch strings.ToLower(p.line(label + hint)) {
+		case "":
+			return def
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+	}
+}
+
+// port asks until parse accepts the answer (see ProjectConfig.ParsePort)
+func (p *prompter) port(label string, def int, parse func(string) (int, error)) int {
+	for {
+		port, err := parse(p.ask(label, strconv.Itoa(def)))
+		if err == nil {
+			return port
+		}
+		fmt.Fprintf(p.out, "  %v\n", err)
+	}
+}
+
+// serviceName asks until the answer is a valid name no service of project
+// has; an empty answer is accepted when optional
+func (p *prompter) serviceName(label, def string, project *scaffold.ProjectConfig, optional bool) string {
+	for {
+		name := scaffold.NormalizeServiceName(p.ask(label, def))
+		switch err := scaffold.ValidateServiceName(name); {
+		case name == "" && optional:
+			return ""
+		case name == "":
+		case err != nil:
+			fmt.Fprintf(p.out, "  %v\n", err)
+		case project.Service(name) != nil:
+			fmt.Fprintf(p.out, "  service %s already exists\n", name)
+		default:
+			return name
+		}
+	}
+}
+
+// entities asks for the entities of service and their fields until an empty
+// entity name; defaults (from --entity) may be kept instead
+func (p *prompter) entities(service string, defaults []scaffold.Entity) []scaffold.Entity {
+	if len(defaults) > 0 {
+		names := make([]string, len(defaults))
+		for i, e := range defaults {
+			names[i] = e.Name
+		}
+		if p.confirm("Entities: "+strings.Join(names, ", ")+". Keep them?", true) {
+			return defaults
+		}
+	}
+
+	fmt.Fprintln(p.out, "Entities (empty name to finish). Fields are name:type, name:optional:type or")
+	fmt.Fprintln(p.out, "name:enum(A,B); types: string, bytes, bool, int32, int64, uint32, uint64, float,")
+	fmt.Fprintln(p.out, "double, timestamp.")
+
+	var entities []scaffold.Entity
+	for {
+		name := p.line("Entity name: ")
+		if name == "" {
+			return entities
+		}
+
+		entity := scaffold.Entity{Name: name}
+		for {
+			spec := p.line("  field (empty to finish): ")
+			if spec == "" {
+				break
+			}
+			field, err := scaffold.ParseEntityField(spec)
+			if err != nil {
+				fmt.Fprintf(p.out, "  %v\n", err)
+				continue
+			}
+			entity.Fields = append(entity.Fields, field)
+		}
+
+		if err := scaffold.ValidateEntities(service, append(entities, entity)); err != nil {
+			fmt.Fprintf(p.out, "  %v, entity skipped\n", err)
+			continue
+		}
+		entities = append(entities, entity)
+	}
+}
+
+// parseEntities parses the --entity flags of service
+func parseEntities(service string, specs []string) ([]scaffold.Entity, error) {
+	var entities []scaffold.Entity
+	for _, spec := range specs {
+		entity, err := scaffold.ParseEntity(spec)
+		if err != nil {
+			return nil, err
+		}
+		entities = append(entities, entity)
+	}
+	return entities, scaffold.ValidateEntities(service, entities)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold"
+)
+
+var removeServiceCmd = &cobra.Command{
+	Use:   "remove-service [service-name]",
+	Short: "Remove a service from the project",
+	Long: `Remove a gRPC service, reversing add-service and make gen-<service>:
+- Deletes src/service/<name> (generated code, hooks and env files)
+- Deletes migrations/<name>
+- Deletes proto/<name> unless --keep-proto is given
+- Drops the service from grpc-gen.yaml and regenerates the Makefile
+
+Asks for confirmation first unless --yes is given.
+
+Example:
+  grpc-gen remove-service order
+  grpc-gen remove-service order --keep-proto --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serviceName := scaffold.NormalizeServiceName(args[0])
+		keepProto, _ := cmd.Flags().GetBool("keep-proto")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		paths, err := scaffold.PlanRemoveService(serviceName, keepProto)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("🗑️  Removing service: %s\n\n", serviceName)
+		for _, path := range paths {
+			note := ""
+			if path.UserFiles > 0 {
+				note = fmt.Sprintf(", %d not generated", path.UserFiles)
+			}
+			fmt.Printf("  - %s (%d files%s)\n", path.Path, path.Files, note)
+		}
+		fmt.Printf("  - %s entry and Makefile targets\n\n", scaffold.ProjectConfigFile)
+
+		if !yes {
+			fmt.Printf("Remove service %s? [y/N] ", serviceName)
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+				fmt.Println("Aborted, nothing removed")
+				return nil
+			}
+		}
+
+		if err := scaffold.RemoveService(serviceName, paths); err != nil {
+			return fmt.Errorf("failed to remove service: %w", err)
+		}
+
+		files := 0
+		for _, path := range paths {
+			files += path.Files
+		}
+		fmt.Printf("\n✅ Service %s removed: %d files in %d directories deleted, Makefile regenerated\n", serviceName, files, len(paths))
+		if keepProto {
+			fmt.Printf("   proto/%s was kept\n", serviceName)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	removeServiceCmd.Flags().Bool("keep-proto", false, "Keep proto/<service>")
+	removeServiceCmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+}
@@ -4,9 +4,11 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold"
 )
 
-const Version = "v0.3.0"
+// Version is the grpc-gen version, the scaffold version of new projects
+const Version = scaffold.Version
 
 var rootCmd = &cobra.Command{
 	Use:   "grpc-gen",
@@ -33,6 +35,14 @@ func Execute() error {
 func init() {
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(addServiceCmd)
+	rootCmd.AddCommand(addEntityCmd)
+	rootCmd.AddCommand(removeServiceCmd)
+	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(genMigrationCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(upgradeCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(watchCmd)
 	rootCmd.AddCommand(versionCmd)
 }
 
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Regenerate the Makefile from grpc-gen.yaml",
+	Long: `Regenerate the Makefile from the project manifest (grpc-gen.yaml) after
+editing services, ports, TLS or migrate modes by hand. make runs it on its own
+when grpc-gen.yaml is newer than the Makefile.
+
+Projects created before the manifest listed services get them imported from
+the old Makefile, which is kept as Makefile.orig.
+
+Env files, Dockerfiles and docker-compose.yml follow on the next
+make gen-<service>.
+
+Example:
+  grpc-gen sync
+  grpc-gen sync --diff`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		diff, _ := cmd.Flags().GetBool("diff")
+
+		if err := scaffold.SyncProject(scaffold.Preview{DryRun: dryRun, Diff: diff}); err != nil {
+			return err
+		}
+
+		if !dryRun && !diff {
+			fmt.Println("✅ Makefile regenerated from " + scaffold.ProjectConfigFile)
+		}
+		return nil
+	},
+}
+
+func init() {
+	syncCmd.Flags().Bool("dry-run", false, "List the files that would be modified without writing them")
+	syncCmd.Flags().Bool("diff", false, "Show a unified diff of the changes without writing them")
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold"
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade the project to this grpc-gen version",
+	Long: `Upgrade the project in the current directory from the scaffold version
+recorded in grpc-gen.yaml to this grpc-gen version.
+
+The upgrade steps between the two versions run in order (moving packages,
+rewriting imports, removing the old per-project generator), then the shared
+protos, pkg files, certificate helpers and ejected templates are three-way
+merged with your edits, using the copies grpc-gen wrote last time
+(.grpc-gen/base, or the files of v0.3.0 for projects it created) as the merge
+base. Overlapping edits are written with <<<<<<< conflict markers and reported.
+Files without a merge base are replaced and your version is kept next to them
+as <file>.orig.
+
+Modules the new pkg files import are added to go.mod. Nothing is written when a
+step fails. Run make gen-all and go mod tidy afterwards to regenerate the
+services with the new templates.
+
+Example:
+  grpc-gen upgrade --diff
+  grpc-gen upgrade`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		diff, _ := cmd.Flags().GetBool("diff")
+		preview := scaffold.Preview{DryRun: dryRun, Diff: diff}
+
+		report, err := scaffold.Upgrade(preview)
+		if err != nil {
+			return err
+		}
+		if len(report.Steps) == 0 {
+			fmt.Printf("✅ Project is up to date (%s)\n", report.To)
+			return nil
+		}
+
+		fmt.Printf("\n⬆️  Upgrade from %s to %s\n", report.From, report.To)
+		for i, step := range report.Steps {
+			fmt.Printf("  %d. %s\n", i+1, step)
+		}
+		if len(report.Changes) > 0 {
+			fmt.Println()
+		}
+		for _, c := range report.Changes {
+			if c.Note != "" {
+				fmt.Printf("  %-8s %s (%s)\n", c.Action, c.Path, c.Note)
+			} else {
+				fmt.Printf("  %-8s %s\n", c.Action, c.Path)
+			}
+		}
+
+		if preview.Enabled() {
+			fmt.Println("\nNothing was written (--dry-run/--diff)")
+			return nil
+		}
+
+		if review := report.Review(); len(review) > 0 {
+			fmt.Printf("\n⚠️  %d file(s) need your attention:\n", len(review))
+			for _, c := range review {
+				fmt.Printf("  %s: %s\n", c.Path, c.Note)
+			}
+		}
+		fmt.Printf("\n✅ Upgraded to %s\n", report.To)
+		fmt.Println("\nNext: make gen-all && go mod tidy && go build ./...")
+		return nil
+	},
+}
+
+func init() {
+	upgradeCmd.Flags().Bool("dry-run", false, "List the files that would be created, modified or deleted without writing them")
+	upgradeCmd.Flags().Bool("diff", false, "Show a unified diff of the changes without writing them")
+}
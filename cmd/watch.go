@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [service-name...]",
+	Short: "Regenerate services when their protos change",
+	Long: `Watch proto/, template/ and grpc-gen.yaml and regenerate the services a
+change affects (the named services, or every service of grpc-gen.yaml):
+
+  proto/<service>/         protoc and grpc-gen generate for that service
+  proto/common, grpcgen    protoc for the shared proto, then every service
+  template/                every service
+  grpc-gen.yaml            the Makefile, then every service
+
+protoc and parse errors are printed and watching goes on; fix the proto and
+save again. --run builds and starts a service, and rebuilds and restarts it
+after it was regenerated or its Go code (hooks, handlers, pkg) changed. The
+running binary is kept when the build fails.
+
+Example:
+  grpc-gen watch
+  grpc-gen watch thesis --run thesis`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		run, _ := cmd.Flags().GetStringArray("run")
+		interval, _ := cmd.Flags().GetDuration("interval")
+
+		for i := range args {
+			args[i] = scaffold.NormalizeServiceName(args[i])
+		}
+		for i := range run {
+			run[i] = scaffold.NormalizeServiceName(run[i])
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return scaffold.Watch(ctx, scaffold.WatchOptions{Services: args, Run: run, Interval: interval})
+	},
+}
+
+func init() {
+	watchCmd.Flags().StringArray("run", nil, "Build, start and restart this service on changes (repeatable)")
+	watchCmd.Flags().Duration("interval", 500*time.Millisecond, "How often to scan the watched files")
+}
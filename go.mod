@@ -2,7 +2,10 @@ module github.com/thailyhcmut/grpc-gen
 
 go 1.24.6
 
-require github.com/spf13/cobra v1.10.1
+require (
+	github.com/spf13/cobra v1.10.1
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
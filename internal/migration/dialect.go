@@ -0,0 +1,179 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect renders DDL statements for one database
+type Dialect interface {
+	Name() string
+	// Quote quotes a table or column name, so reserved words such as user
+	// can be used as names
+	Quote(name string) string
+	ColumnType(c Column) string
+	CreateTable(t Table) string
+	DropTable(t Table) string
+	AddColumn(table string, c Column) string
+	DropColumn(table, column string) string
+	// AlterColumn changes the type and nullability of a column from old to new
+	AlterColumn(table string, old, new Column) string
+	CreateIndex(table, column string) string
+	DropIndex(table, column string) string
+}
+
+// GetDialect returns the dialect registered under name
+func GetDialect(name string) (Dialect, error) {
+	switch name {
+	case "", "mysql":
+		return mysqlDialect{}, nil
+	case "postgres":
+		return postgresDialect{}, nil
+	case "sqlite":
+		return sqliteDialect{}, nil
+	}
+	return nil, fmt.Errorf("unsupported database dialect %q", name)
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) Quote(name string) string { return quoteIdent(name, '`') }
+
+func (mysqlDialect) ColumnType(c Column) string {
+	switch c.Kind {
+	case KindString:
+		if c.PrimaryKey {
+			return "VARCHAR(36)"
+		}
+		return "VARCHAR(255)"
+	case KindInt32:
+		return "INT"
+	case KindInt64:
+		return "BIGINT"
+	case KindUint32:
+		return "INT UNSIGNED"
+	case KindUint64:
+		return "BIGINT UNSIGNED"
+	case KindFloat:
+		return "FLOAT"
+	case KindDouble:
+		return "DOUBLE"
+	case KindBool:
+		return "BOOLEAN"
+	case KindBytes:
+		return "BLOB"
+	case KindEnum:
+		return "ENUM(" + quoteValues(c.EnumValues) + ")"
+	case KindTimestamp:
+		return "DATETIME"
+	}
+	return "TEXT"
+}
+
+func (d mysqlDialect) CreateTable(t Table) string {
+	var lines []string
+	for _, c := range t.Columns {
+		lines = append(lines, "  "+columnDefinition(d, c))
+	}
+	lines = append(lines, "  "+primaryKey(d))
+	return fmt.Sprintf("CREATE TABLE %s (\n%s\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n", d.Quote(t.Name), strings.Join(lines, ",\n")) +
+		createIndexes(d, t)
+}
+
+func (d mysqlDialect) DropTable(t Table) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", d.Quote(t.Name))
+}
+
+func (d mysqlDialect) AddColumn(table string, c Column) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;\n", d.Quote(table), columnDefinition(d, c))
+}
+
+func (d mysqlDialect) DropColumn(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;\n", d.Quote(table), d.Quote(column))
+}
+
+func (d mysqlDialect) AlterColumn(table string, old, new Column) string {
+	return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s;\n", d.Quote(table), columnDefinition(d, new))
+}
+
+func (d mysqlDialect) CreateIndex(table, column string) string {
+	return fmt.Sprintf("CREATE INDEX %s ON %s (%s);\n", d.Quote(indexName(table, column)), d.Quote(table), d.Quote(column))
+}
+
+func (d mysqlDialect) DropIndex(table, column string) string {
+	return fmt.Sprintf("DROP INDEX %s ON %s;\n", d.Quote(indexName(table, column)), d.Quote(table))
+}
+
+// quoteIdent quotes each part of a (schema-qualified) name with q, doubling
+// quote characters inside it
+func quoteIdent(name string, q byte) string {
+	quote := string(q)
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = quote + strings.ReplaceAll(part, quote, quote+quote) + quote
+	}
+	return strings.Join(parts, ".")
+}
+
+// primaryKey renders the primary key clause of CREATE TABLE
+func primaryKey(d Dialect) string {
+	return "PRIMARY KEY (" + d.Quote("id") + ")"
+}
+
+// indexName is the name of the (grpcgen.index) index of a column. The schema
+// of a qualified table name becomes part of the name.
+func indexName(table, column string) string {
+	return "idx_" + strings.ReplaceAll(table, ".", "_") + "_" + column
+}
+
+// createIndexes renders the CREATE INDEX statements of the indexed columns of
+// t; dropping the table drops them too
+func createIndexes(d Dialect, t Table) string {
+	var statements strings.Builder
+	for _, c := range t.Columns {
+		if c.Indexed {
+			statements.WriteString(d.CreateIndex(t.Name, c.Name))
+		}
+	}
+	return statements.String()
+}
+
+// columnDefinition renders "<name> <type> [NOT] NULL"
+func columnDefinition(d Dialect, c Column) string {
+	def := d.Quote(c.Name) + " " + d.ColumnType(c)
+	if c.Nullable {
+		return def + " NULL"
+	}
+	return def + " NOT NULL"
+}
+
+// zeroValue is the default of a NOT NULL column added to a table, which
+// PostgreSQL and SQLite require as soon as the table has rows (MySQL fills in
+// the same implicit values)
+func zeroValue(c Column) string {
+	switch c.Kind {
+	case KindString, KindBytes:
+		return "''"
+	case KindEnum:
+		if len(c.EnumValues) > 0 {
+			return quoteValues(c.EnumValues[:1])
+		}
+		return "''"
+	case KindBool:
+		return "FALSE"
+	case KindTimestamp:
+		// ADD COLUMN only accepts constant defaults
+		return "'1970-01-01 00:00:00'"
+	}
+	return "0"
+}
+
+func quoteValues(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
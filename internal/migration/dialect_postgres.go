@@ -0,0 +1,119 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// postgresDialect stores enums as VARCHAR with a CHECK constraint named
+// <table>_<column>_check, so enum changes only swap the constraint
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (postgresDialect) Quote(name string) string { return quoteIdent(name, '"') }
+
+func (postgresDialect) ColumnType(c Column) string {
+	switch c.Kind {
+	case KindString:
+		if c.PrimaryKey {
+			return "VARCHAR(36)"
+		}
+		return "VARCHAR(255)"
+	case KindInt32:
+		return "INTEGER"
+	case KindInt64, KindUint32:
+		return "BIGINT"
+	case KindUint64:
+		return "NUMERIC(20)"
+	case KindFloat:
+		return "REAL"
+	case KindDouble:
+		return "DOUBLE PRECISION"
+	case KindBool:
+		return "BOOLEAN"
+	case KindBytes:
+		return "BYTEA"
+	case KindEnum:
+		return "VARCHAR(64)"
+	case KindTimestamp:
+		return "TIMESTAMP"
+	}
+	return "TEXT"
+}
+
+func (d postgresDialect) CreateTable(t Table) string {
+	var lines []string
+	for _, c := range t.Columns {
+		lines = append(lines, "  "+columnDefinition(d, c))
+	}
+	lines = append(lines, "  "+primaryKey(d))
+	for _, c := range t.Columns {
+		if c.Kind == KindEnum {
+			lines = append(lines, "  "+d.enumConstraint(t.Name, c))
+		}
+	}
+	return fmt.Sprintf("CREATE TABLE %s (\n%s\n);\n", d.Quote(t.Name), strings.Join(lines, ",\n")) + createIndexes(d, t)
+}
+
+func (d postgresDialect) DropTable(t Table) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", d.Quote(t.Name))
+}
+
+// AddColumn gives NOT NULL columns a default, without which adding them
+// fails on a table with rows
+func (d postgresDialect) AddColumn(table string, c Column) string {
+	def := columnDefinition(d, c)
+	if !c.Nullable {
+		def += " DEFAULT " + zeroValue(c)
+	}
+	actions := []string{"ADD COLUMN " + def}
+	if c.Kind == KindEnum {
+		actions = append(actions, "ADD "+d.enumConstraint(table, c))
+	}
+	return fmt.Sprintf("ALTER TABLE %s %s;\n", d.Quote(table), strings.Join(actions, ", "))
+}
+
+func (d postgresDialect) DropColumn(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;\n", d.Quote(table), d.Quote(column))
+}
+
+func (d postgresDialect) AlterColumn(table string, old, new Column) string {
+	var actions []string
+	if old.Kind == KindEnum {
+		actions = append(actions, "DROP CONSTRAINT IF EXISTS "+d.Quote(constraintName(table, old.Name)))
+	}
+	column := d.Quote(new.Name)
+	if oldType, newType := d.ColumnType(old), d.ColumnType(new); oldType != newType {
+		actions = append(actions, fmt.Sprintf("ALTER COLUMN %s TYPE %s USING %s::%s", column, newType, column, newType))
+	}
+	if old.Nullable != new.Nullable {
+		if new.Nullable {
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s DROP NOT NULL", column))
+		} else {
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s SET NOT NULL", column))
+		}
+	}
+	if new.Kind == KindEnum {
+		actions = append(actions, "ADD "+d.enumConstraint(table, new))
+	}
+	return fmt.Sprintf("ALTER TABLE %s %s;\n", d.Quote(table), strings.Join(actions, ", "))
+}
+
+func (d postgresDialect) CreateIndex(table, column string) string {
+	return fmt.Sprintf("CREATE INDEX %s ON %s (%s);\n", d.Quote(indexName(table, column)), d.Quote(table), d.Quote(column))
+}
+
+func (d postgresDialect) DropIndex(table, column string) string {
+	return fmt.Sprintf("DROP INDEX IF EXISTS %s;\n", d.Quote(indexName(table, column)))
+}
+
+// enumConstraint renders the CHECK constraint listing the values of an enum column
+func (d postgresDialect) enumConstraint(table string, c Column) string {
+	return fmt.Sprintf("CONSTRAINT %s CHECK (%s IN (%s))", d.Quote(constraintName(table, c.Name)), d.Quote(c.Name), quoteValues(c.EnumValues))
+}
+
+// constraintName is the name of the CHECK constraint of an enum column
+func constraintName(table, column string) string {
+	return strings.ReplaceAll(table, ".", "_") + "_" + column + "_check"
+}
@@ -0,0 +1,78 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqliteDialect targets SQLite 3.35+. Enums are checked inline; timestamps use
+// DATETIME so the driver scans them back into time.Time. SQLite cannot change
+// a column in place, so AlterColumn only emits a note to rebuild the table.
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+func (sqliteDialect) Quote(name string) string { return quoteIdent(name, '"') }
+
+func (d sqliteDialect) ColumnType(c Column) string {
+	switch c.Kind {
+	case KindString:
+		if c.PrimaryKey {
+			return "VARCHAR(36)"
+		}
+		return "VARCHAR(255)"
+	case KindInt32, KindUint32:
+		return "INTEGER"
+	case KindInt64, KindUint64:
+		return "BIGINT"
+	case KindFloat, KindDouble:
+		return "REAL"
+	case KindBool:
+		return "BOOLEAN"
+	case KindBytes:
+		return "BLOB"
+	case KindEnum:
+		return fmt.Sprintf("VARCHAR(64) CHECK (%s IN (%s))", d.Quote(c.Name), quoteValues(c.EnumValues))
+	case KindTimestamp:
+		return "DATETIME"
+	}
+	return "TEXT"
+}
+
+func (d sqliteDialect) CreateTable(t Table) string {
+	var lines []string
+	for _, c := range t.Columns {
+		lines = append(lines, "  "+columnDefinition(d, c))
+	}
+	lines = append(lines, "  "+primaryKey(d))
+	return fmt.Sprintf("CREATE TABLE %s (\n%s\n);\n", d.Quote(t.Name), strings.Join(lines, ",\n")) + createIndexes(d, t)
+}
+
+func (d sqliteDialect) DropTable(t Table) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", d.Quote(t.Name))
+}
+
+// AddColumn gives NOT NULL columns a default, which SQLite requires
+func (d sqliteDialect) AddColumn(table string, c Column) string {
+	def := columnDefinition(d, c)
+	if !c.Nullable {
+		def += " DEFAULT " + zeroValue(c)
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;\n", d.Quote(table), def)
+}
+
+func (d sqliteDialect) DropColumn(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;\n", d.Quote(table), d.Quote(column))
+}
+
+func (d sqliteDialect) AlterColumn(table string, old, new Column) string {
+	return fmt.Sprintf("-- SQLite cannot alter columns: rebuild %s with %s\n", table, columnDefinition(d, new))
+}
+
+func (d sqliteDialect) CreateIndex(table, column string) string {
+	return fmt.Sprintf("CREATE INDEX %s ON %s (%s);\n", d.Quote(indexName(table, column)), d.Quote(table), d.Quote(column))
+}
+
+func (d sqliteDialect) DropIndex(table, column string) string {
+	return fmt.Sprintf("DROP INDEX IF EXISTS %s;\n", d.Quote(indexName(table, column)))
+}
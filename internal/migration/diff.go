@@ -0,0 +1,235 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// snapshotFile stores the schema the last migration was generated from
+const snapshotFile = ".schema.json"
+
+// Snapshot is the content of migrations/<service>/.schema.json
+type Snapshot struct {
+	Dialect string  `json:"dialect"`
+	Tables  []Table `json:"tables"`
+}
+
+// Change is one schema difference between the snapshot and the proto
+type Change struct {
+	Table       string
+	Description string
+	// Destructive changes can lose data or fail on existing rows
+	Destructive bool
+	Up          string
+	Down        string
+}
+
+func (c Change) String() string {
+	return c.Table + ": " + c.Description
+}
+
+// loadSnapshot reads the snapshot in dir; it returns nil if there is none
+func loadSnapshot(dir string) (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(dir, snapshotFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", filepath.Join(dir, snapshotFile), err)
+	}
+	return &snapshot, nil
+}
+
+func saveSnapshot(dir string, snapshot Snapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, snapshotFile), append(data, '\n'), 0644)
+}
+
+// Diff compares two schemas and returns the changes needed to go from old to
+// new. Changes are ordered by table, columns in declaration order.
+func Diff(d Dialect, old, new []Table) []Change {
+	var changes []Change
+
+	oldTables := make(map[string]Table)
+	for _, t := range old {
+		oldTables[t.Name] = t
+	}
+	newTables := make(map[string]bool)
+
+	for _, t := range new {
+		newTables[t.Name] = true
+		prev, ok := oldTables[t.Name]
+		if !ok {
+			changes = append(changes, Change{
+				Table:       t.Name,
+				Description: "create table",
+				Up:          d.CreateTable(t),
+				Down:        d.DropTable(t),
+			})
+			continue
+		}
+		changes = append(changes, diffColumns(d, prev, t)...)
+	}
+
+	for _, t := range old {
+		if !newTables[t.Name] {
+			changes = append(changes, Change{
+				Table:       t.Name,
+				Description: "drop table",
+				Destructive: true,
+				Up:          d.DropTable(t),
+				Down:        d.CreateTable(t),
+			})
+		}
+	}
+
+	return changes
+}
+
+func diffColumns(d Dialect, old, new Table) []Change {
+	var changes []Change
+
+	oldColumns := make(map[string]Column)
+	for _, c := range old.Columns {
+		oldColumns[c.Name] = c
+	}
+	newColumns := make(map[string]bool)
+
+	for _, c := range new.Columns {
+		newColumns[c.Name] = true
+		prev, ok := oldColumns[c.Name]
+		if !ok {
+			changes = append(changes, Change{
+				Table:       new.Name,
+				Description: "add column " + c.Name,
+				Up:          d.AddColumn(new.Name, c) + createIndex(d, new.Name, c),
+				Down:        dropIndex(d, new.Name, c) + d.DropColumn(new.Name, c.Name),
+			})
+			continue
+		}
+		if change, ok := diffColumn(d, new.Name, prev, c); ok {
+			changes = append(changes, change)
+		}
+		if prev.Indexed != c.Indexed {
+			change := Change{
+				Table:       new.Name,
+				Description: "add index on " + c.Name,
+				Up:          d.CreateIndex(new.Name, c.Name),
+				Down:        d.DropIndex(new.Name, c.Name),
+			}
+			if !c.Indexed {
+				change.Description = "drop index on " + c.Name
+				change.Up, change.Down = change.Down, change.Up
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	for _, c := range old.Columns {
+		if !newColumns[c.Name] {
+			changes = append(changes, Change{
+				Table:       new.Name,
+				Description: "drop column " + c.Name,
+				Destructive: true,
+				Up:          dropIndex(d, new.Name, c) + d.DropColumn(new.Name, c.Name),
+				Down:        d.AddColumn(new.Name, c) + createIndex(d, new.Name, c),
+			})
+		}
+	}
+
+	return changes
+}
+
+// createIndex and dropIndex render the index statement of c, if it is
+// indexed. SQLite refuses to drop an indexed column, so the index goes first.
+func createIndex(d Dialect, table string, c Column) string {
+	if !c.Indexed {
+		return ""
+	}
+	return d.CreateIndex(table, c.Name)
+}
+
+func dropIndex(d Dialect, table string, c Column) string {
+	if !c.Indexed {
+		return ""
+	}
+	return d.DropIndex(table, c.Name)
+}
+
+// diffColumn describes a change to an existing column, if any
+func diffColumn(d Dialect, table string, old, new Column) (Change, bool) {
+	var descriptions []string
+	destructive := false
+
+	if old.Kind != new.Kind {
+		descriptions = append(descriptions, fmt.Sprintf("change type %s -> %s", old.Kind, new.Kind))
+		destructive = true
+	}
+
+	if old.Nullable != new.Nullable {
+		if new.Nullable {
+			descriptions = append(descriptions, "make nullable")
+		} else {
+			// Fails (or rewrites data) if the column already holds NULLs
+			descriptions = append(descriptions, "make NOT NULL")
+			destructive = true
+		}
+	}
+
+	if old.Kind == KindEnum && new.Kind == KindEnum && strings.Join(old.EnumValues, ",") != strings.Join(new.EnumValues, ",") {
+		added, removed := compareValues(old.EnumValues, new.EnumValues)
+		if len(added) > 0 {
+			descriptions = append(descriptions, "add enum values "+strings.Join(added, ", "))
+		}
+		if len(removed) > 0 {
+			descriptions = append(descriptions, "remove enum values "+strings.Join(removed, ", "))
+			destructive = true
+		}
+		if len(added) == 0 && len(removed) == 0 {
+			descriptions = append(descriptions, "reorder enum values")
+		}
+	}
+
+	if len(descriptions) == 0 {
+		return Change{}, false
+	}
+	return Change{
+		Table:       table,
+		Description: "alter column " + new.Name + " (" + strings.Join(descriptions, ", ") + ")",
+		Destructive: destructive,
+		Up:          d.AlterColumn(table, old, new),
+		Down:        d.AlterColumn(table, new, old),
+	}, true
+}
+
+// compareValues returns the values only in new and the values only in old
+func compareValues(old, new []string) (added, removed []string) {
+	oldSet := make(map[string]bool)
+	for _, v := range old {
+		oldSet[v] = true
+	}
+	newSet := make(map[string]bool)
+	for _, v := range new {
+		newSet[v] = true
+		if !oldSet[v] {
+			added = append(added, v)
+		}
+	}
+	for _, v := range old {
+		if !newSet[v] {
+			removed = append(removed, v)
+		}
+	}
+	return added, removed
+}
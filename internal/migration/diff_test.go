@@ -0,0 +1,132 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := []Table{{Name: "users", Columns: []Column{
+		{Name: "id", Kind: KindString, PrimaryKey: true},
+		{Name: "name", Kind: KindString, Nullable: true},
+		{Name: "role", Kind: KindEnum, EnumValues: []string{"admin", "user"}},
+		{Name: "legacy", Kind: KindInt32},
+	}}, {Name: "sessions", Columns: []Column{{Name: "id", Kind: KindString, PrimaryKey: true}}}}
+
+	new := []Table{{Name: "users", Columns: []Column{
+		{Name: "id", Kind: KindString, PrimaryKey: true},
+		{Name: "name", Kind: KindString},
+		{Name: "role", Kind: KindEnum, EnumValues: []string{"admin", "user", "guest"}},
+		{Name: "phone", Kind: KindString, Nullable: true},
+	}}}
+
+	changes := Diff(mysqlDialect{}, old, new)
+	var got []string
+	for _, c := range changes {
+		line := c.String()
+		if c.Destructive {
+			line = "!" + line
+		}
+		got = append(got, line)
+	}
+
+	want := []string{
+		"!users: alter column name (make NOT NULL)",
+		"users: alter column role (add enum values guest)",
+		"users: add column phone",
+		"!users: drop column legacy",
+		"!sessions: drop table",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Diff() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if changes[1].Up != "ALTER TABLE `users` MODIFY COLUMN `role` ENUM('admin', 'user', 'guest') NOT NULL;\n" {
+		t.Errorf("enum up = %q", changes[1].Up)
+	}
+	if changes[2].Down != "ALTER TABLE `users` DROP COLUMN `phone`;\n" {
+		t.Errorf("add column down = %q", changes[2].Down)
+	}
+}
+
+func TestDiffIndexes(t *testing.T) {
+	old := []Table{{Name: "users", Columns: []Column{
+		{Name: "id", Kind: KindString, PrimaryKey: true},
+		{Name: "email", Kind: KindString},
+		{Name: "name", Kind: KindString, Indexed: true},
+		{Name: "legacy", Kind: KindInt32, Indexed: true},
+	}}}
+	new := []Table{{Name: "users", Columns: []Column{
+		{Name: "id", Kind: KindString, PrimaryKey: true},
+		{Name: "email", Kind: KindString, Indexed: true},
+		{Name: "name", Kind: KindString},
+		{Name: "phone", Kind: KindString, Nullable: true, Indexed: true},
+	}}}
+
+	var got []string
+	for _, c := range Diff(sqliteDialect{}, old, new) {
+		got = append(got, c.String(), "  up: "+c.Up, "  down: "+c.Down)
+	}
+	want := `users: add index on email
+  up: CREATE INDEX "idx_users_email" ON "users" ("email");
+
+  down: DROP INDEX IF EXISTS "idx_users_email";
+
+users: drop index on name
+  up: DROP INDEX IF EXISTS "idx_users_name";
+
+  down: CREATE INDEX "idx_users_name" ON "users" ("name");
+
+users: add column phone
+  up: ALTER TABLE "users" ADD COLUMN "phone" VARCHAR(255) NULL;
+CREATE INDEX "idx_users_phone" ON "users" ("phone");
+
+  down: DROP INDEX IF EXISTS "idx_users_phone";
+ALTER TABLE "users" DROP COLUMN "phone";
+
+users: drop column legacy
+  up: DROP INDEX IF EXISTS "idx_users_legacy";
+ALTER TABLE "users" DROP COLUMN "legacy";
+
+  down: ALTER TABLE "users" ADD COLUMN "legacy" INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX "idx_users_legacy" ON "users" ("legacy");
+`
+	if strings.Join(got, "\n") != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", strings.Join(got, "\n"), want)
+	}
+
+	if got := (mysqlDialect{}).DropIndex("users", "email"); got != "DROP INDEX `idx_users_email` ON `users`;\n" {
+		t.Errorf("mysql DropIndex() = %q", got)
+	}
+}
+
+func TestDiffAddNotNullColumn(t *testing.T) {
+	old := []Table{{Name: "users", Columns: []Column{{Name: "id", Kind: KindString, PrimaryKey: true}}}}
+	new := []Table{{Name: "users", Columns: []Column{
+		{Name: "id", Kind: KindString, PrimaryKey: true},
+		{Name: "active", Kind: KindBool},
+		{Name: "role", Kind: KindEnum, EnumValues: []string{"admin", "user"}},
+		{Name: "nickname", Kind: KindString, Nullable: true},
+	}}}
+
+	var up []string
+	for _, c := range Diff(postgresDialect{}, old, new) {
+		if c.Destructive {
+			t.Errorf("%s is destructive", c)
+		}
+		up = append(up, c.Up)
+	}
+	want := `ALTER TABLE "users" ADD COLUMN "active" BOOLEAN NOT NULL DEFAULT FALSE;` + "\n" +
+		`ALTER TABLE "users" ADD COLUMN "role" VARCHAR(64) NOT NULL DEFAULT 'admin', ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('admin', 'user'));` + "\n" +
+		`ALTER TABLE "users" ADD COLUMN "nickname" VARCHAR(255) NULL;` + "\n"
+	if got := strings.Join(up, ""); got != want {
+		t.Errorf("up =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	tables := []Table{{Name: "users", Columns: []Column{{Name: "id", Kind: KindString, PrimaryKey: true}}}}
+	if changes := Diff(mysqlDialect{}, tables, tables); len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+}
@@ -0,0 +1,209 @@
+package migration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationFileRegex matches versioned migration files, e.g. 000001_create_tables.up.sql
+var migrationFileRegex = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Options configures a gen-migration run
+type Options struct {
+	Service    string
+	ModulePath string
+	Dialect    string
+	// AllowDestructive permits drops, NOT NULL changes and removed enum values
+	AllowDestructive bool
+}
+
+// Result describes a gen-migration run
+type Result struct {
+	Changes []Change
+	Files   []string
+}
+
+// Generate compares the entity messages of a service with the schema snapshot
+// in migrations/<service>/.schema.json and writes the next numbered up/down
+// migration. The first run creates every table; later runs emit ALTER
+// statements. Destructive changes are refused unless opts.AllowDestructive.
+func Generate(opts Options) (*Result, error) {
+	dialect, err := GetDialect(opts.Dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := LoadTables(opts.Service, opts.ModulePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no CRUD entities found in proto/%s/%s.proto", opts.Service, opts.Service)
+	}
+
+	dir := filepath.Join("migrations", opts.Service)
+	versions, err := existingVersions(dir)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := loadSnapshot(dir)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil && len(versions) > 0 {
+		return nil, fmt.Errorf("%s contains migrations but no %s snapshot to diff against", dir, snapshotFile)
+	}
+
+	if snapshot != nil && snapshot.Dialect != "" && snapshot.Dialect != dialect.Name() {
+		return nil, fmt.Errorf("%s was generated for %s, not %s", dir, snapshot.Dialect, dialect.Name())
+	}
+
+	name := "update_" + opts.Service + "_schema"
+	var previous []Table
+	if snapshot == nil {
+		name = "create_" + opts.Service + "_tables"
+	} else {
+		previous = snapshot.Tables
+	}
+
+	result := &Result{Changes: Diff(dialect, previous, tables)}
+	if len(result.Changes) == 0 {
+		return result, nil
+	}
+
+	if !opts.AllowDestructive {
+		var destructive []string
+		for _, change := range result.Changes {
+			if change.Destructive {
+				destructive = append(destructive, "  - "+change.String())
+			}
+		}
+		if len(destructive) > 0 {
+			return result, fmt.Errorf("destructive schema changes (re-run with --allow-destructive to generate them):\n%s", strings.Join(destructive, "\n"))
+		}
+	}
+
+	var up, down strings.Builder
+	for i, change := range result.Changes {
+		// Keep CREATE TABLE statements apart from what precedes them
+		if i > 0 && strings.HasPrefix(change.Up, "CREATE TABLE") {
+			up.WriteString("\n")
+		}
+		up.WriteString(change.Up)
+	}
+	// Undo in reverse order
+	for i := len(result.Changes) - 1; i >= 0; i-- {
+		down.WriteString(result.Changes[i].Down)
+	}
+
+	result.Files, err = writeMigration(dir, nextVersion(versions), name, up.String(), down.String())
+	if err != nil {
+		return nil, err
+	}
+	if err := saveSnapshot(dir, Snapshot{Dialect: dialect.Name(), Tables: tables}); err != nil {
+		return nil, fmt.Errorf("failed to save schema snapshot: %w", err)
+	}
+	result.Files = append(result.Files, filepath.Join(dir, snapshotFile))
+
+	// The service binary embeds the directory through this package
+	embedFile, err := EnsureEmbedPackage(opts.Service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrations package: %w", err)
+	}
+	if embedFile != "" {
+		result.Files = append(result.Files, embedFile)
+	}
+	return result, nil
+}
+
+// existingVersions returns the versions of migrations already in dir, sorted
+func existingVersions(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool)
+	var versions []int
+	for _, entry := range entries {
+		m := migrationFileRegex.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(m[1])
+		if !seen[version] {
+			seen[version] = true
+			versions = append(versions, version)
+		}
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+func nextVersion(versions []int) int {
+	if len(versions) == 0 {
+		return 1
+	}
+	return versions[len(versions)-1] + 1
+}
+
+// writeMigration writes <version>_<name>.up.sql and .down.sql
+func writeMigration(dir string, version int, name, up, down string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%06d_%s", version, name))
+	files := []string{base + ".up.sql", base + ".down.sql"}
+	for i, content := range []string{up, down} {
+		if err := os.WriteFile(files[i], []byte(content), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", files[i], err)
+		}
+	}
+	return files, nil
+}
+
+// EmbedSource embeds the SQL files of migrations/<service> so the service
+// binary can apply them (see database.NewMigrator)
+const EmbedSource = `// Code generated by grpc-gen. DO NOT EDIT.
+
+// Package migrations embeds the SQL migrations written by grpc-gen gen-migration.
+package migrations
+
+import "embed"
+
+// FS holds the <version>_<name>.up.sql / .down.sql files of this directory
+//
+//go:embed *
+var FS embed.FS
+`
+
+// EmbedFile is the path of the package embedding the migrations of a service
+func EmbedFile(service string) string {
+	return filepath.Join("migrations", service, "embed.go")
+}
+
+// EnsureEmbedPackage creates migrations/<service>/embed.go if it does not
+// exist and returns its path when it was created
+func EnsureEmbedPackage(service string) (string, error) {
+	filename := EmbedFile(service)
+	if _, err := os.Stat(filename); err == nil {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filename, []byte(EmbedSource), 0644); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
@@ -0,0 +1,143 @@
+package migration
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/parser"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/types"
+)
+
+// Column kinds, independent of the SQL dialect
+const (
+	KindString    = "string"
+	KindInt32     = "int32"
+	KindInt64     = "int64"
+	KindUint32    = "uint32"
+	KindUint64    = "uint64"
+	KindFloat     = "float"
+	KindDouble    = "double"
+	KindBool      = "bool"
+	KindBytes     = "bytes"
+	KindEnum      = "enum"
+	KindTimestamp = "timestamp"
+)
+
+// Table is the schema of one entity table
+type Table struct {
+	Name    string   `json:"name"`
+	Entity  string   `json:"entity"`
+	Columns []Column `json:"columns"`
+}
+
+// Column is a single table column
+type Column struct {
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind"`
+	Nullable   bool     `json:"nullable"`
+	PrimaryKey bool     `json:"primary_key,omitempty"`
+	Indexed    bool     `json:"indexed,omitempty"`     // (grpcgen.index)
+	EnumValues []string `json:"enum_values,omitempty"` // database values (see types.Field.EnumDBValue)
+}
+
+// scalarKinds maps proto scalar types to column kinds
+var scalarKinds = map[string]string{
+	"string":   KindString,
+	"int32":    KindInt32,
+	"sint32":   KindInt32,
+	"sfixed32": KindInt32,
+	"int64":    KindInt64,
+	"sint64":   KindInt64,
+	"sfixed64": KindInt64,
+	"uint32":   KindUint32,
+	"fixed32":  KindUint32,
+	"uint64":   KindUint64,
+	"fixed64":  KindUint64,
+	"float":    KindFloat,
+	"double":   KindDouble,
+	"bool":     KindBool,
+	"bytes":    KindBytes,
+}
+
+// LoadTables parses proto/<service>/<service>.proto and returns the table of
+// every entity with full CRUD methods, sorted by table name
+func LoadTables(service, modulePath string) ([]Table, error) {
+	protoFile := filepath.Join("proto", service, service+".proto")
+	registry, err := parser.LoadRegistry(".", protoFile, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	file := registry.File(protoFile)
+	if err := parser.ValidateOptions(file); err != nil {
+		return nil, err
+	}
+
+	entityFields := parser.GetEntityFields(file, registry)
+	tableNames := parser.GetEntityTableNames(file)
+
+	var tables []Table
+	for entity, methods := range parser.GroupMethodsByEntity(parser.GetMethods(file, registry)) {
+		if !parser.IsCRUDEntity(methods) {
+			continue
+		}
+		name := tableNames[entity]
+		if name == "" {
+			name = entity
+		}
+		table, err := buildTable(name, entity, entityFields[entity])
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+	return tables, nil
+}
+
+// buildTable maps entity fields to columns. The system columns match what the
+// generated CRUD handler reads and writes.
+func buildTable(name, entity string, fields []types.Field) (Table, error) {
+	table := Table{Name: name, Entity: entity}
+	table.Columns = append(table.Columns, Column{Name: "id", Kind: KindString, PrimaryKey: true})
+
+	for _, field := range fields {
+		column := Column{Name: field.DBField, Nullable: field.IsOptional, Indexed: field.IsIndexed}
+		switch {
+		case field.IsEnum:
+			column.Kind = KindEnum
+			stored := make(map[string]string)
+			for _, value := range field.EnumValues {
+				dbValue := field.EnumDBValue(value)
+				if other, ok := stored[dbValue]; ok {
+					return Table{}, fmt.Errorf("%s.%s: enum values %s and %s are both stored as %q", entity, field.Name, other, value, dbValue)
+				}
+				stored[dbValue] = value
+				column.EnumValues = append(column.EnumValues, dbValue)
+			}
+		case field.IsTimestamp:
+			// Not written by the handler, so it must accept NULL
+			column.Kind = KindTimestamp
+			column.Nullable = true
+		default:
+			kind, ok := scalarKinds[field.Type]
+			if !ok {
+				return Table{}, fmt.Errorf("%s.%s: type %s has no column mapping (mark it with (grpcgen.skip_db) = true)", entity, field.Name, field.Type)
+			}
+			column.Kind = kind
+		}
+		if column.Indexed && column.Kind == KindBytes {
+			return Table{}, fmt.Errorf("%s.%s: bytes columns cannot be indexed", entity, field.Name)
+		}
+		table.Columns = append(table.Columns, column)
+	}
+
+	table.Columns = append(table.Columns,
+		Column{Name: "created_at", Kind: KindTimestamp},
+		Column{Name: "updated_at", Kind: KindTimestamp},
+		Column{Name: "created_by", Kind: KindString, Nullable: true},
+		Column{Name: "updated_by", Kind: KindString, Nullable: true},
+	)
+	return table, nil
+}
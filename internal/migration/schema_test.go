@@ -0,0 +1,138 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/types"
+)
+
+func TestMySQLCreateTable(t *testing.T) {
+	table, err := buildTable("topics", "Topic", []types.Field{
+		{Name: "title", Type: "string", DBField: "title"},
+		{Name: "score", Type: "int64", DBField: "score", IsOptional: true},
+		{Name: "status", Type: "TopicStatus", DBField: "status", IsEnum: true, EnumValues: []string{"DRAFT", "DONE"}},
+		{Name: "deadline", Type: "google.protobuf.Timestamp", DBField: "deadline", IsTimestamp: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "CREATE TABLE `topics` (\n" +
+		"  `id` VARCHAR(36) NOT NULL,\n" +
+		"  `title` VARCHAR(255) NOT NULL,\n" +
+		"  `score` BIGINT NULL,\n" +
+		"  `status` ENUM('draft', 'done') NOT NULL,\n" +
+		"  `deadline` DATETIME NULL,\n" +
+		"  `created_at` DATETIME NOT NULL,\n" +
+		"  `updated_at` DATETIME NOT NULL,\n" +
+		"  `created_by` VARCHAR(255) NULL,\n" +
+		"  `updated_by` VARCHAR(255) NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n"
+	if got := (mysqlDialect{}).CreateTable(table); got != want {
+		t.Errorf("CreateTable() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBuildTableUnmappedType(t *testing.T) {
+	_, err := buildTable("topics", "Topic", []types.Field{{Name: "owner", Type: "user.User", DBField: "owner"}})
+	if err == nil {
+		t.Fatal("expected error for message-typed field")
+	}
+}
+
+func TestBuildTableIndexedBytes(t *testing.T) {
+	_, err := buildTable("topics", "Topic", []types.Field{{Name: "data", Type: "bytes", DBField: "data", IsIndexed: true}})
+	if err == nil || !strings.Contains(err.Error(), "bytes columns cannot be indexed") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestPostgresCreateTable(t *testing.T) {
+	table, err := buildTable("topics", "Topic", []types.Field{
+		{Name: "title", Type: "string", DBField: "title", IsIndexed: true},
+		{Name: "status", Type: "TopicStatus", DBField: "status", IsEnum: true, EnumValues: []string{"DRAFT", "DONE"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `CREATE TABLE "topics" (
+  "id" VARCHAR(36) NOT NULL,
+  "title" VARCHAR(255) NOT NULL,
+  "status" VARCHAR(64) NOT NULL,
+  "created_at" TIMESTAMP NOT NULL,
+  "updated_at" TIMESTAMP NOT NULL,
+  "created_by" VARCHAR(255) NULL,
+  "updated_by" VARCHAR(255) NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "topics_status_check" CHECK ("status" IN ('draft', 'done'))
+);
+CREATE INDEX "idx_topics_title" ON "topics" ("title");
+`
+	if got := (postgresDialect{}).CreateTable(table); got != want {
+		t.Errorf("CreateTable() =\n%s\nwant\n%s", got, want)
+	}
+
+	status := table.Columns[2]
+	widened := status
+	widened.EnumValues = []string{"draft", "done", "archived"}
+	alter := (postgresDialect{}).AlterColumn("topics", status, widened)
+	if alter != `ALTER TABLE "topics" DROP CONSTRAINT IF EXISTS "topics_status_check", ADD CONSTRAINT "topics_status_check" CHECK ("status" IN ('draft', 'done', 'archived'));`+"\n" {
+		t.Errorf("AlterColumn() = %q", alter)
+	}
+}
+
+func TestSQLiteAddColumn(t *testing.T) {
+	d := sqliteDialect{}
+
+	enum := Column{Name: "status", Kind: KindEnum, EnumValues: []string{"draft", "done"}}
+	if got, want := d.AddColumn("topics", enum), `ALTER TABLE "topics" ADD COLUMN "status" VARCHAR(64) CHECK ("status" IN ('draft', 'done')) NOT NULL DEFAULT 'draft';`+"\n"; got != want {
+		t.Errorf("AddColumn(enum) = %q, want %q", got, want)
+	}
+
+	optional := Column{Name: "score", Kind: KindInt64, Nullable: true}
+	if got, want := d.AddColumn("topics", optional), `ALTER TABLE "topics" ADD COLUMN "score" BIGINT NULL;`+"\n"; got != want {
+		t.Errorf("AddColumn(optional) = %q, want %q", got, want)
+	}
+}
+
+func TestQuoteReservedAndQualifiedNames(t *testing.T) {
+	users := Table{Name: "auth.User", Columns: []Column{
+		{Name: "id", Kind: KindString, PrimaryKey: true},
+		{Name: "order", Kind: KindInt32, Indexed: true},
+	}}
+
+	want := `CREATE TABLE "auth"."User" (
+  "id" VARCHAR(36) NOT NULL,
+  "order" INTEGER NOT NULL,
+  PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_auth_User_order" ON "auth"."User" ("order");
+`
+	if got := (postgresDialect{}).CreateTable(users); got != want {
+		t.Errorf("CreateTable() =\n%s\nwant\n%s", got, want)
+	}
+	if got, want := (mysqlDialect{}).DropColumn("User", "order"), "ALTER TABLE `User` DROP COLUMN `order`;\n"; got != want {
+		t.Errorf("DropColumn() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildTableEnumPrefix(t *testing.T) {
+	status := types.Field{Name: "status", Type: "EnrollmentStatus", DBField: "status", IsEnum: true, EnumValuePrefix: "ENROLLMENT_STATUS_",
+		EnumValues: []string{"ENROLLMENT_STATUS_ACTIVE", "DROPPED"}}
+	table, err := buildTable("enrollments", "Enrollment", []types.Field{status})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(table.Columns[1].EnumValues, ","); got != "active,dropped" {
+		t.Errorf("EnumValues = %s, want active,dropped", got)
+	}
+
+	status.EnumValues = []string{"ENROLLMENT_STATUS_ACTIVE", "ACTIVE"}
+	_, err = buildTable("enrollments", "Enrollment", []types.Field{status})
+	if err == nil || !strings.Contains(err.Error(), `enum values ENROLLMENT_STATUS_ACTIVE and ACTIVE are both stored as "active"`) {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -2,8 +2,8 @@ package scaffold
 
 import (
 	"embed"
+	"errors"
 	"fmt"
-	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -13,218 +13,214 @@ import (
 //go:embed assets/*
 var assetsFS embed.FS
 
-func copyTemplates() error {
-	// Copy template files from assets
-	templates := []string{
-		"crud_handler.tmpl",
-		"dockerfile.tmpl",
-		"docker-compose.tmpl",
-		"entity_handler.tmpl",
-		"env.tmpl",
-		"handler.tmpl",
-		"main.tmpl",
+// templateNames lists the templates the generator reads
+var templateNames = []string{
+	"crud_handler.tmpl",
+	"crud_handler_test.tmpl",
+	"crud_hooks.tmpl",
+	"dockerfile.tmpl",
+	"docker-compose.tmpl",
+	"entity_handler.tmpl",
+	"env.tmpl",
+	"env_gen.tmpl",
+	"handler.tmpl",
+	"handler_test.tmpl",
+	"hooks.tmpl",
+	"main.tmpl",
+}
+
+// EjectTemplates copies the embedded templates the project's template/
+// directory does not have yet, so they can be customized, and returns their
+// paths. grpc-gen upgrade merges later versions into them.
+func EjectTemplates() ([]string, error) {
+	if err := os.MkdirAll("template", 0755); err != nil {
+		return nil, err
 	}
 
-	for _, tmpl := range templates {
+	var written []string
+	for _, tmpl := range templateNames {
+		path := filepath.Join("template", tmpl)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+
 		data, err := assetsFS.ReadFile("assets/" + tmpl)
 		if err != nil {
-			return fmt.Errorf("failed to read template %s: %w", tmpl, err)
+			return nil, fmt.Errorf("failed to read template %s: %w", tmpl, err)
 		}
-
-		if err := os.WriteFile(filepath.Join("template", tmpl), data, 0644); err != nil {
-			return fmt.Errorf("failed to write template %s: %w", tmpl, err)
+		if err := writeScaffoldFile(scaffoldFile{path: path, content: data, perm: 0644}); err != nil {
+			return nil, fmt.Errorf("failed to write template %s: %w", tmpl, err)
 		}
+		written = append(written, path)
 	}
 
-	return nil
+	return written, nil
 }
 
-// copyPkgFiles copies pkg directory from assets/template/pkg to src/service/pkg
-// and replaces hardcoded module paths with actual module path
-func copyPkgFiles() error {
-	srcPath := "assets/template/pkg"
-	dstPath := filepath.Join("src", "service", "pkg")
-
-	// Get module path from go.mod
-	modulePath, err := getModulePath()
-	if err != nil {
-		return fmt.Errorf("failed to get module path: %w", err)
-	}
-
-	// Copy directory with path replacement
-	return copyEmbedDirWithReplace(srcPath, dstPath, map[string]string{
-		"thaily/proto/common": modulePath + "/proto/common",
-	})
+// templateFS serves the project's template/ files over the templates embedded
+// in this binary
+func templateFS() fs.FS {
+	embedded, _ := fs.Sub(assetsFS, "assets")
+	return overlayFS{os.DirFS("template"), embedded}
 }
 
-// getModulePath reads module path from go.mod
-func getModulePath() (string, error) {
-	data, err := os.ReadFile("go.mod")
-	if err != nil {
-		return "", err
-	}
+// overlayFS opens a file from the first FS that has it
+type overlayFS []fs.FS
 
-	lines := strings.Split(string(data), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "module ") {
-			return strings.TrimSpace(strings.TrimPrefix(line, "module ")), nil
+func (o overlayFS) Open(name string) (fs.File, error) {
+	for _, fsys := range o {
+		f, err := fsys.Open(name)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return f, err
 		}
 	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
 
-	return "", fmt.Errorf("module path not found in go.mod")
+// baseDir keeps the scaffold files as grpc-gen last wrote them, the merge base
+// of grpc-gen upgrade. The go tool ignores directories starting with a dot.
+const baseDir = ".grpc-gen/base"
+
+// scaffoldFile is a file grpc-gen writes once and upgrade merges with the
+// user's edits
+type scaffoldFile struct {
+	path    string
+	content []byte
+	perm    os.FileMode
 }
 
-// copyEmbedDirWithReplace copies embedded directory and replaces strings in files
-func copyEmbedDirWithReplace(srcPath, dstPath string, replacements map[string]string) error {
-	return fs.WalkDir(assetsFS, srcPath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
+// scaffoldFiles renders the shared protos (proto/common, proto/grpcgen), pkg
+// utilities (src/service/pkg) and certificate helpers of a project. Module paths are replaced with modulePath and only the
+// database drivers of dialect and SQLite are kept.
+func scaffoldFiles(modulePath, dialect string) ([]scaffoldFile, error) {
+	srcPath := "assets/template/pkg"
+	dstPath := filepath.Join("src", "service", "pkg")
 
-		if path == srcPath {
-			return nil
+	// Dialect files of other database servers are left out so their drivers
+	// are not linked in; SQLite is kept for local runs and generated tests
+	skip := make(map[string]bool)
+	for _, d := range Dialects {
+		if d != dialect && d != "sqlite" {
+			skip[filepath.Join(srcPath, "database", "dialect_"+d+".go")] = true
 		}
+	}
 
-		relPath, err := filepath.Rel(srcPath, path)
-		if err != nil {
+	files := []scaffoldFile{
+		{path: filepath.Join("proto", "common", "common.proto"), content: commonProto(modulePath), perm: 0644},
+		{path: filepath.Join("proto", "grpcgen", "options.proto"), content: optionsProto(modulePath), perm: 0644},
+	}
+	err := fs.WalkDir(assetsFS, srcPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || skip[path] {
 			return err
 		}
 
-		dstFilePath := filepath.Join(dstPath, relPath)
-
-		if d.IsDir() {
-			return os.MkdirAll(dstFilePath, 0755)
-		}
-
-		// Read file
 		data, err := assetsFS.ReadFile(path)
 		if err != nil {
 			return err
 		}
-
-		// Replace strings in .go files
 		if strings.HasSuffix(path, ".go") {
-			content := string(data)
-			for old, new := range replacements {
-				content = strings.ReplaceAll(content, old, new)
-			}
-			data = []byte(content)
+			data = []byte(strings.NewReplacer(
+				"thaily/proto/common", modulePath+"/proto/common",
+				"thaily/src/service/pkg", modulePath+"/src/service/pkg",
+			).Replace(string(data)))
 		}
 
-		return os.WriteFile(dstFilePath, data, 0644)
+		relPath, err := filepath.Rel(srcPath, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, scaffoldFile{path: filepath.Join(dstPath, relPath), content: data, perm: 0644})
+		return nil
 	})
-}
-
-// copyCertsSetup copies CERTS_SETUP.md from assets
-func copyCertsSetup() error {
-	data, err := assetsFS.ReadFile("assets/template/CERTS_SETUP.md")
-	if err != nil {
-		return fmt.Errorf("failed to read CERTS_SETUP.md: %w", err)
-	}
-
-	if err := os.WriteFile("CERTS_SETUP.md", data, 0644); err != nil {
-		return fmt.Errorf("failed to write CERTS_SETUP.md: %w", err)
-	}
-
-	return nil
-}
-
-// copyGenerateCertsScript copies generate-certs.sh script from assets
-func copyGenerateCertsScript() error {
-	data, err := assetsFS.ReadFile("assets/template/generate-certs.sh")
 	if err != nil {
-		return fmt.Errorf("failed to read generate-certs.sh: %w", err)
+		return nil, err
 	}
 
-	if err := os.WriteFile("generate-certs.sh", data, 0755); err != nil {
-		return fmt.Errorf("failed to write generate-certs.sh: %w", err)
-	}
-
-	return nil
-}
+	files = append(files, scaffoldFile{
+		path: filepath.Join(dstPath, "database", "driver.go"),
+		content: []byte(fmt.Sprintf(`package database
 
-func copyGeneratorScripts() error {
-	// Copy all scripts directories
-	scriptDirs := []string{"types", "parser", "generator", "utils"}
-
-	for _, dir := range scriptDirs {
-		srcPath := "assets/scripts/" + dir
-		dstPath := filepath.Join("scripts", dir)
+// DefaultDriver is the database selected with grpc-gen init --db; DB_DRIVER overrides it
+const DefaultDriver = %q
+`, dialect)),
+		perm: 0644,
+	})
 
-		if err := copyEmbedDir(srcPath, dstPath); err != nil {
-			return fmt.Errorf("failed to copy %s: %w", dir, err)
+	for _, helper := range []struct {
+		name string
+		perm os.FileMode
+	}{{"CERTS_SETUP.md", 0644}, {"generate-certs.sh", 0755}} {
+		data, err := assetsFS.ReadFile("assets/template/" + helper.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", helper.name, err)
 		}
+		files = append(files, scaffoldFile{path: helper.name, content: data, perm: helper.perm})
 	}
 
-	// Copy main gen_skeleton.go
-	data, err := assetsFS.ReadFile("assets/scripts/gen_skeleton.go")
-	if err != nil {
-		return fmt.Errorf("failed to read gen_skeleton.go: %w", err)
-	}
-
-	if err := os.WriteFile(filepath.Join("scripts", "gen_skeleton.go"), data, 0644); err != nil {
-		return fmt.Errorf("failed to write gen_skeleton.go: %w", err)
-	}
-
-	// Create go.mod for scripts
-	goModContent := `module gen_skeleton
+	return files, nil
+}
 
-go 1.24
-`
-	if err := os.WriteFile(filepath.Join("scripts", "go.mod"), []byte(goModContent), 0644); err != nil {
-		return fmt.Errorf("failed to write scripts/go.mod: %w", err)
+// previousScaffoldFiles returns the files a release that did not record
+// merge bases wrote into a project (scaffold files, template copies and the
+// Makefile of init), by project path with module paths replaced with
+// modulePath. They are kept under assets/_previous/<version>; nil when the
+// release is not kept.
+func previousScaffoldFiles(version, modulePath string) (map[string][]byte, error) {
+	srcPath := "assets/_previous/" + version
+	if _, err := fs.Stat(assetsFS, srcPath); err != nil {
+		return nil, nil
 	}
 
-	return nil
-}
+	files := make(map[string][]byte)
+	err := fs.WalkDir(assetsFS, srcPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
 
-func copyEmbedDir(srcPath, dstPath string) error {
-	return fs.WalkDir(assetsFS, srcPath, func(path string, d fs.DirEntry, err error) error {
+		data, err := assetsFS.ReadFile(path)
 		if err != nil {
 			return err
 		}
-
-		// Skip the root directory itself
-		if path == srcPath {
-			return nil
+		if strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".proto") {
+			data = []byte(strings.ReplaceAll(string(data), "thaily/proto/common", modulePath+"/proto/common"))
 		}
 
-		// Calculate relative path
 		relPath, err := filepath.Rel(srcPath, path)
 		if err != nil {
 			return err
 		}
+		files[relPath] = data
+		return nil
+	})
+	return files, err
+}
 
-		dstFilePath := filepath.Join(dstPath, relPath)
-
-		if d.IsDir() {
-			return os.MkdirAll(dstFilePath, 0755)
-		}
-
-		// Copy file
-		data, err := assetsFS.ReadFile(path)
-		if err != nil {
+// writeScaffoldFile writes f and records it as the merge base of upgrade
+func writeScaffoldFile(f scaffoldFile) error {
+	for _, path := range []string{f.path, filepath.Join(baseDir, f.path)} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 			return err
 		}
-
-		return os.WriteFile(dstFilePath, data, 0644)
-	})
+	}
+	if err := os.WriteFile(f.path, f.content, f.perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.path, err)
+	}
+	return os.WriteFile(filepath.Join(baseDir, f.path), f.content, 0644)
 }
 
-// Helper function to copy regular files (for non-embedded use)
-func copyFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
+// getModulePath reads module path from go.mod
+func getModulePath() (string, error) {
+	data, err := os.ReadFile("go.mod")
 	if err != nil {
-		return err
+		return "", err
 	}
-	defer sourceFile.Close()
 
-	destFile, err := os.Create(dst)
-	if err != nil {
-		return err
+	lines := strings.Split(string(data), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module ")), nil
+		}
 	}
-	defer destFile.Close()
 
-	_, err = io.Copy(destFile, sourceFile)
-	return err
+	return "", fmt.Errorf("module path not found in go.mod")
 }
@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type contextKey string
+
+const (
+	requestIDKey contextKey = "request_id"
+)
+
+// WithRequestID adds a request ID to the context
+func WithRequestID(ctx context.Context) context.Context {
+	requestID := uuid.New().String()
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// GetRequestID gets the request ID from context
+func GetRequestID(ctx context.Context) string {
+	if requestID, ok := ctx.Value(requestIDKey).(string); ok {
+		return requestID
+	}
+	return ""
+}
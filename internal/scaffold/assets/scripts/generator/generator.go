@@ -7,79 +7,53 @@ import (
 	"strings"
 	"text/template"
 
-	"gen_skeleton/types"
+	"github.com/thailyhcmut/grpc-gen/internal/migration"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/types"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/utils"
 )
 
-// GenerateMain creates main.go from template
-func GenerateMain(serviceDir string, data types.Data) {
-	tmpl, err := template.ParseFiles("template/main.tmpl")
-	if err != nil {
-		log.Fatal(err)
-	}
+// GenerateMain creates main_gen.go from template and, on the first run,
+// hooks.go with the server hooks it calls
+func GenerateMain(serviceDir string, data types.Data) error {
+	tmpl := parseTemplate("main.tmpl", nil)
 
-	out, err := os.Create(filepath.Join(serviceDir, "main.go"))
-	if err != nil {
-		log.Fatal(err)
+	if err := writeGenerated(filepath.Join(serviceDir, "main_gen.go"), tmpl, data); err != nil {
+		return err
 	}
-	defer out.Close()
 
-	if err := tmpl.Execute(out, data); err != nil {
-		log.Fatal(err)
-	}
+	hooks := parseTemplate("hooks.tmpl", nil)
 
-	log.Printf("Generated %s/main.go\n", serviceDir)
+	writeOnce(filepath.Join(serviceDir, "hooks.go"), hooks, data)
+	return nil
 }
 
-// GenerateHandlerRoot creates handler/handler.go from template
-func GenerateHandlerRoot(handlerDir string, data types.HandlerData) {
-	tmpl, err := template.ParseFiles("template/handler.tmpl")
-	if err != nil {
-		log.Fatal(err)
-	}
+// GenerateHandlerRoot creates handler/handler_gen.go from template
+func GenerateHandlerRoot(handlerDir string, data types.HandlerData) error {
+	tmpl := parseTemplate("handler.tmpl", nil)
 
-	out, err := os.Create(filepath.Join(handlerDir, "handler.go"))
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer out.Close()
-
-	if err := tmpl.Execute(out, data); err != nil {
-		log.Fatal(err)
-	}
-
-	log.Printf("Generated %s/handler.go\n", handlerDir)
+	return writeGenerated(filepath.Join(handlerDir, "handler_gen.go"), tmpl, data)
 }
 
-// GenerateEntityHandler creates a simple entity handler from template
+// GenerateEntityHandler creates the stubs of a non-CRUD entity on the first
+// run; the file belongs to the user afterwards
 func GenerateEntityHandler(handlerDir string, data types.EntityHandlerData) {
-	tmpl, err := template.ParseFiles("template/entity_handler.tmpl")
-	if err != nil {
-		log.Fatal(err)
-	}
+	tmpl := parseTemplate("entity_handler.tmpl", nil)
 
 	filename := strings.ToLower(data.EntityName) + ".go"
-	out, err := os.Create(filepath.Join(handlerDir, filename))
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer out.Close()
-
-	if err := tmpl.Execute(out, data); err != nil {
-		log.Fatal(err)
-	}
-
-	log.Printf("Generated %s/%s\n", handlerDir, filename)
+	writeOnce(filepath.Join(handlerDir, filename), tmpl, data)
 }
 
-// GenerateCRUDHandler creates a full CRUD handler from template
-func GenerateCRUDHandler(handlerDir, packagePath, entityName string, methods []types.Method, fields []types.Field, enums map[string][]string, requiredFieldsMap map[string][]string, optionalFieldsMap map[string][]string, optionalEntityFieldsMap map[string][]string, optionalUpdateFieldsMap map[string][]string, modulePath string) {
+// GenerateCRUDHandler creates handler/<entity>_gen.go with the full CRUD
+// handler and, on the first run, handler/<entity>.go for its hooks
+func GenerateCRUDHandler(handlerDir, packagePath, entityName, tableName, testSchema string, methods []types.Method, fields []types.Field, imports []types.GoImport, requiredFieldsMap map[string][]string, optionalFieldsMap map[string][]string, optionalEntityFieldsMap map[string][]string, optionalUpdateFieldsMap map[string][]string, pageTokenResponses map[string]bool, modulePath string) error {
 	// Prepare data for template
 	requiredFields := []types.Field{}
 	optionalFields := []types.Field{}
 	enumFields := []types.Field{}
 	createFields := []types.Field{}
 	updateFields := []types.Field{}
-	filterableFields := []string{}
+	filterableFields := []types.Field{}
+	sortableFields := []types.Field{}
 	scanFields := []string{}
 
 	var enumType string
@@ -106,12 +80,15 @@ func GenerateCRUDHandler(handlerDir, packagePath, entityName string, methods []t
 			enumType = field.EnumType
 		}
 
-		if !field.IsTimestamp {
-			filterableFields = append(filterableFields, field.DBField)
+		if field.IsFilterable {
+			filterableFields = append(filterableFields, *field)
+		}
+		if field.IsSortable {
+			sortableFields = append(sortableFields, *field)
 		}
 
 		// Mark field as optional if it's in optionalFieldsMap (MUST DO THIS FIRST)
-		if optionalFieldNames[field.DBField] {
+		if optionalFieldNames[field.ProtoName] {
 			field.IsOptional = true
 		}
 
@@ -121,9 +98,9 @@ func GenerateCRUDHandler(handlerDir, packagePath, entityName string, methods []t
 		}
 
 		// Check if field is required based on CreateRequest
-		if requiredFieldNames[field.DBField] && !field.IsEnum {
+		if requiredFieldNames[field.ProtoName] && !field.IsEnum {
 			requiredFields = append(requiredFields, *field)
-		} else if optionalFieldNames[field.DBField] && !field.IsTimestamp {
+		} else if optionalFieldNames[field.ProtoName] && !field.IsTimestamp {
 			optionalFields = append(optionalFields, *field)
 		}
 
@@ -158,7 +135,7 @@ func GenerateCRUDHandler(handlerDir, packagePath, entityName string, methods []t
 			continue
 		} else {
 			// Check if this field is optional in the entity
-			if optionalEntityFieldsSet[field.DBField] {
+			if optionalEntityFieldsSet[field.ProtoName] {
 				// Use NullString/NullInt32 variable for optional entity fields
 				scanFields = append(scanFields, field.GoName+"Null")
 				optionalEntityFieldsData = append(optionalEntityFieldsData, field)
@@ -175,13 +152,15 @@ func GenerateCRUDHandler(handlerDir, packagePath, entityName string, methods []t
 
 	for _, field := range fields {
 		if !field.IsTimestamp {
-			createFieldNames = append(createFieldNames, field.DBField)
+			createFieldNames = append(createFieldNames, quoteIdent(field.DBField))
 			createPlaceholders = append(createPlaceholders, "?")
 		}
 	}
 
-	selectFields = append([]string{"id"}, createFieldNames...)
-	selectFields = append(selectFields, "created_at", "updated_at", "created_by", "updated_by")
+	selectFields = append([]string{quoteIdent("id")}, createFieldNames...)
+	for _, column := range []string{"created_at", "updated_at", "created_by", "updated_by"} {
+		selectFields = append(selectFields, quoteIdent(column))
+	}
 
 	// Get optional update fields
 	optionalUpdateFields := []string{}
@@ -209,47 +188,57 @@ func GenerateCRUDHandler(handlerDir, packagePath, entityName string, methods []t
 		}
 	}
 
+	hasNextPageToken := false
+	listField := utils.Pluralize(entityName)
+	for _, method := range methods {
+		if strings.HasPrefix(method.Name, "List") {
+			hasNextPageToken = hasNextPageToken || pageTokenResponses[method.ResponseType]
+			if method.ListField != "" {
+				listField = method.ListField
+			}
+		}
+	}
+
 	data := types.CRUDHandlerData{
-		ModulePath:           modulePath,
-		PackagePath:          packagePath,
-		EntityName:           entityName,
-		TableName:            entityName,
-		Methods:              methods,
-		EnumType:             enumType,
-		RequiredFields:       requiredFields,
-		OptionalFields:       optionalFields,
-		EnumFields:           enumFields,
-		FilterableFields:     filterableFields,
-		CreateFields:         createFields,
-		CreateFieldsSQL:      strings.Join(createFieldNames, ", "),
-		CreatePlaceholders:   strings.Join(createPlaceholders, ", "),
-		UpdateFields:         updateFields,
-		SelectFieldsSQL:         strings.Join(selectFields, ", "),
-		ScanFields:              scanFields,
-		OptionalEntityFields:    optionalEntityFields,
+		ModulePath:               modulePath,
+		PackagePath:              packagePath,
+		Imports:                  imports,
+		EntityName:               entityName,
+		TableName:                tableName,
+		Methods:                  methods,
+		EnumType:                 enumType,
+		RequiredFields:           requiredFields,
+		OptionalFields:           optionalFields,
+		EnumFields:               enumFields,
+		FilterableFields:         filterableFields,
+		SortableFields:           sortableFields,
+		CreateFields:             createFields,
+		CreateFieldsSQL:          strings.Join(createFieldNames, ", "),
+		CreatePlaceholders:       strings.Join(createPlaceholders, ", "),
+		UpdateFields:             updateFields,
+		SelectFieldsSQL:          strings.Join(selectFields, ", "),
+		ScanFields:               scanFields,
+		OptionalEntityFields:     optionalEntityFields,
 		OptionalEntityFieldsData: optionalEntityFieldsData,
-		OptionalUpdateFields:    optionalUpdateFields,
-		IsCreatedByOptional:  isCreatedByOptional,
-		IsUpdatedByOptional:  isUpdatedByOptional,
+		OptionalUpdateFields:     optionalUpdateFields,
+		IsCreatedByOptional:      isCreatedByOptional,
+		IsUpdatedByOptional:      isUpdatedByOptional,
+		HasNextPageToken:         hasNextPageToken,
+		ListField:                listField,
 	}
 
 	// Create template with custom functions
 	funcMap := template.FuncMap{
-		"lower":     strings.ToLower,
-		"hasPrefix": strings.HasPrefix,
-		"pluralize": func(s string) string {
-			// Pluralize keeping first letter uppercase (Go proto convention)
-			if len(s) == 0 {
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"lowerFirst": func(s string) string {
+			if s == "" {
 				return s
 			}
-			// Check if ends with consonant + y
-			if strings.HasSuffix(s, "y") && len(s) > 1 {
-				// Faculty -> Faculties
-				return s[:len(s)-1] + "ies"
-			}
-			// Default: just add s
-			return s + "s"
+			return strings.ToLower(s[:1]) + s[1:]
 		},
+		"hasPrefix": strings.HasPrefix,
+		"quote":     quoteIdent,
 		"isOptionalEntity": func(fieldName string, optionalFields []string) bool {
 			for _, opt := range optionalFields {
 				if opt == fieldName {
@@ -268,31 +257,31 @@ func GenerateCRUDHandler(handlerDir, packagePath, entityName string, methods []t
 		},
 	}
 
-	tmpl, err := template.New("crud_handler.tmpl").Funcs(funcMap).ParseFiles("template/crud_handler.tmpl")
-	if err != nil {
-		log.Fatal(err)
-	}
+	tmpl := parseTemplate("crud_handler.tmpl", funcMap)
 
-	filename := strings.ToLower(entityName) + ".go"
-	out, err := os.Create(filepath.Join(handlerDir, filename))
-	if err != nil {
-		log.Fatal(err)
+	filename := strings.ToLower(entityName) + "_gen.go"
+	if err := writeGenerated(filepath.Join(handlerDir, filename), tmpl, data); err != nil {
+		return err
 	}
-	defer out.Close()
 
-	if err := tmpl.Execute(out, data); err != nil {
-		log.Fatal(err)
-	}
+	hooks := parseTemplate("crud_hooks.tmpl", funcMap)
 
-	log.Printf("Generated CRUD handler %s/%s\n", handlerDir, filename)
+	hooksFile := strings.ToLower(entityName) + ".go"
+	writeOnce(filepath.Join(handlerDir, hooksFile), hooks, data)
+
+	return generateCRUDHandlerTest(handlerDir, data, testSchema, funcMap)
+}
+
+// quoteIdent double-quotes a table or column name (each part of
+// schema.table) in generated SQL, so reserved words such as user work as
+// names; database.Rebind turns the quotes into backticks on MySQL
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, ".", `"."`) + `"`
 }
 
 // GenerateEnvFile creates .env file from template
 func GenerateEnvFile(protoName string, data types.Data) {
-	tmpl, err := template.ParseFiles("template/env.tmpl")
-	if err != nil {
-		log.Fatal(err)
-	}
+	tmpl := parseTemplate("env.tmpl", nil)
 
 	filename := filepath.Join("env", protoName+".env")
 	out, err := os.Create(filename)
@@ -305,81 +294,58 @@ func GenerateEnvFile(protoName string, data types.Data) {
 		log.Fatal(err)
 	}
 
-	log.Printf("Generated %s\n", filename)
 }
 
 // GenerateDockerfile creates Dockerfile from template
-func GenerateDockerfile(protoName string, data types.Data) {
-	tmpl, err := template.ParseFiles("template/dockerfile.tmpl")
-	if err != nil {
-		log.Fatal(err)
-	}
+func GenerateDockerfile(protoName string, data types.Data) error {
+	tmpl := parseTemplate("dockerfile.tmpl", nil)
 
 	filename := filepath.Join("src", "service", protoName, "Dockerfile")
-	out, err := os.Create(filename)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer out.Close()
-
-	if err := tmpl.Execute(out, data); err != nil {
-		log.Fatal(err)
-	}
-
-	log.Printf("Generated %s\n", filename)
+	return writeGenerated(filename, tmpl, data)
 }
 
 // GenerateDockerCompose creates docker-compose.yml from template
-func GenerateDockerCompose(protoName string, data types.Data) {
-	tmpl, err := template.ParseFiles("template/docker-compose.tmpl")
-	if err != nil {
-		log.Fatal(err)
-	}
+func GenerateDockerCompose(protoName string, data types.Data) error {
+	tmpl := parseTemplate("docker-compose.tmpl", nil)
 
 	filename := filepath.Join("src", "service", protoName, "docker-compose.yml")
-	out, err := os.Create(filename)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer out.Close()
-
-	if err := tmpl.Execute(out, data); err != nil {
-		log.Fatal(err)
-	}
-
-	log.Printf("Generated %s\n", filename)
+	return writeGenerated(filename, tmpl, data)
 }
 
-// GenerateGitignore creates .gitignore from template
-func GenerateGitignore(protoName string) {
-	content := `log/
+// gitignoreTemplate is the content of the service .gitignore
+var gitignoreTemplate = template.Must(template.New("gitignore").Parse(`log/
 *.log
-`
-	filename := filepath.Join("src", "service", protoName, ".gitignore")
-	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
-		log.Fatal(err)
-	}
+`))
 
-	log.Printf("Generated %s\n", filename)
+// GenerateGitignore creates the service .gitignore
+func GenerateGitignore(protoName string) error {
+	filename := filepath.Join("src", "service", protoName, ".gitignore")
+	return writeGenerated(filename, gitignoreTemplate, nil)
 }
 
-// GenerateServiceEnvFile creates service-level .env file
-func GenerateServiceEnvFile(protoName string, data types.Data) {
-	tmpl, err := template.ParseFiles("template/env.tmpl")
-	if err != nil {
-		log.Fatal(err)
+// GenerateServiceEnvFile creates <service>_gen.env with the settings that
+// come from grpc-gen.yaml and, on the first run, <service>.env for
+// credentials; later runs leave the latter alone
+func GenerateServiceEnvFile(protoName string, data types.Data) error {
+	genTmpl := parseTemplate("env_gen.tmpl", nil)
+	if err := writeGenerated(filepath.Join("src", "service", protoName, protoName+"_gen.env"), genTmpl, data); err != nil {
+		return err
 	}
 
-	filename := filepath.Join("src", "service", protoName, protoName+".env")
-	out, err := os.Create(filename)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer out.Close()
+	tmpl := parseTemplate("env.tmpl", nil)
+	writeOnce(filepath.Join("src", "service", protoName, protoName+".env"), tmpl, data)
+	return nil
+}
 
-	if err := tmpl.Execute(out, data); err != nil {
-		log.Fatal(err)
+// EnsureMigrationsPackage creates migrations/<protoName>/embed.go if it does
+// not exist and returns its path when it was created
+func EnsureMigrationsPackage(protoName string) (string, error) {
+	if Previewing() {
+		filename := migration.EmbedFile(protoName)
+		if _, err := os.Stat(filename); err != nil {
+			preview(filename, nil, []byte(migration.EmbedSource))
+		}
+		return "", nil
 	}
-
-	log.Printf("Generated %s\n", filename)
+	return migration.EnsureEmbedPackage(protoName)
 }
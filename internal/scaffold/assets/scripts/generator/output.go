@@ -0,0 +1,197 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/utils"
+)
+
+// GeneratedHeader marks a file owned by the generator. Files carrying it are
+// rewritten on every run; any other file is user code and is never
+// overwritten unless Force is set.
+const GeneratedHeader = "Code generated by grpc-gen. DO NOT EDIT."
+
+// Force lets the generator overwrite files without GeneratedHeader and remove
+// files left over from the layout before *_gen.go (grpc-gen generate --force)
+var Force bool
+
+// DryRun renders everything in memory and only lists the files that would
+// be created, modified or deleted (grpc-gen generate --dry-run)
+var DryRun bool
+
+// Diff is DryRun plus a unified diff of each change (grpc-gen generate --diff)
+var Diff bool
+
+// changed and unchanged count the files seen in preview mode
+var changed, unchanged int
+
+// Previewing reports whether files are left untouched (DryRun or Diff)
+func Previewing() bool {
+	return DryRun || Diff
+}
+
+// PreviewSummary describes the files a preview run would change
+func PreviewSummary() string {
+	return fmt.Sprintf("%d file(s) would change, %d unchanged (nothing written)", changed, unchanged)
+}
+
+// generatedRegex matches the Go convention for generated files, also with
+// # comments for Dockerfiles and YAML
+var generatedRegex = regexp.MustCompile(`(?m)^(//|#) Code generated .* DO NOT EDIT\.$`)
+
+// IsGenerated reports whether the file at path carries a generated-code header
+func IsGenerated(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	return generatedRegex.Match(content), nil
+}
+
+// Preflight checks the files a run is about to touch before anything is
+// written, so a refused run leaves the service unchanged:
+//   - generated must not exist, or must carry the generated header
+//   - legacy maps a file of the old single-file layout to a declaration that
+//     now lives in a *_gen.go file; if the file still contains it, the
+//     build would break with duplicate declarations
+//
+// With Force the legacy files are removed and every conflict is ignored.
+func Preflight(generated []string, legacy map[string]string) error {
+	var conflicts []string
+
+	for _, path := range generated {
+		ok, err := IsGenerated(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			conflicts = append(conflicts, path+": no generated header (edited or hand-written)")
+		}
+	}
+
+	legacyPaths := make([]string, 0, len(legacy))
+	for path := range legacy {
+		legacyPaths = append(legacyPaths, path)
+	}
+	sort.Strings(legacyPaths)
+
+	for _, path := range legacyPaths {
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(content), legacy[path]) {
+			continue
+		}
+		if Force {
+			if Previewing() {
+				preview(path, content, nil)
+				continue
+			}
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			log.Printf("Removed %s (now generated into *_gen.go)\n", path)
+			continue
+		}
+		conflicts = append(conflicts, fmt.Sprintf("%s: declares %q, which is now generated (move custom code to the hooks file)", path, legacy[path]))
+	}
+
+	if len(conflicts) == 0 || Force {
+		return nil
+	}
+	return fmt.Errorf("refusing to overwrite user code:\n  %s\nrerun with grpc-gen generate --force to overwrite", strings.Join(conflicts, "\n  "))
+}
+
+// writeGenerated renders tmpl into path below GeneratedHeader. A file
+// without the header is left alone unless Force is set.
+func writeGenerated(path string, tmpl *template.Template, data any) error {
+	if ok, err := IsGenerated(path); err == nil && !ok && !Force {
+		return fmt.Errorf("refusing to overwrite %s: no generated header (rerun with grpc-gen generate --force)", path)
+	}
+
+	comment := "#"
+	if filepath.Ext(path) == ".go" {
+		comment = "//"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(comment + " " + GeneratedHeader + "\n\n")
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	writeFile(path, buf.Bytes(), "Generated")
+	return nil
+}
+
+// writeOnce renders tmpl into path unless the file exists; files written
+// this way belong to the user
+func writeOnce(path string, tmpl *template.Template, data any) {
+	if _, err := os.Stat(path); err == nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		log.Fatal(err)
+	}
+
+	writeFile(path, buf.Bytes(), "Created")
+}
+
+// writeFile writes content to path and logs it with verb, or in preview mode
+// reports the change instead
+func writeFile(path string, content []byte, verb string) {
+	if Previewing() {
+		old, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			log.Fatal(err)
+		}
+		preview(path, old, content)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("%s %s\n", verb, path)
+}
+
+// preview prints one line per changed file (create, modify or delete when
+// content is nil) and, with Diff, the unified diff
+func preview(path string, old, content []byte) {
+	action := "modify"
+	switch {
+	case content == nil:
+		action = "delete"
+	case old == nil:
+		action = "create"
+	case bytes.Equal(old, content):
+		unchanged++
+		return
+	}
+	changed++
+
+	fmt.Printf("%-7s %s\n", action, path)
+	if Diff {
+		fmt.Print(utils.UnifiedDiff(path, string(old), string(content)))
+	}
+}
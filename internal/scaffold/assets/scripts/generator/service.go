@@ -0,0 +1,225 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/thailyhcmut/grpc-gen/internal/migration"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/parser"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/types"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/utils"
+)
+
+// GenerateService generates the skeleton of the service in settings from
+// proto/<ProtoName>/<ProtoName>.proto. settings carries the proto and service
+// names, the port and the modes from grpc-gen.yaml; the module and package
+// paths are read from go.mod.
+func GenerateService(settings types.Data) {
+	changed, unchanged = 0, 0
+	protoName := settings.ProtoName     // e.g.: academic
+	serviceName := settings.ServiceName // e.g.: AcademicService
+
+	// Get module path from go.mod
+	modulePath, err := utils.GetModulePath()
+	if err != nil {
+		log.Fatalf("Failed to get module path: %v", err)
+	}
+
+	packagePath := modulePath + "/proto/" + protoName
+
+	// Parse proto file (and everything it imports) into the descriptor model
+	// shared by every step below
+	protoFile := filepath.Join("proto", protoName, protoName+".proto")
+	registry, err := parser.LoadRegistry(".", protoFile, modulePath)
+	if err != nil {
+		log.Fatalf("Failed to parse proto file: %v", err)
+	}
+	file := registry.File(protoFile)
+	if err := parser.ValidateOptions(file); err != nil {
+		log.Fatalf("Invalid grpcgen options: %v", err)
+	}
+
+	// Get RPC methods
+	methods := parser.GetMethods(file, registry)
+
+	// Group methods by entity
+	entityMethods := parser.GroupMethodsByEntity(methods)
+
+	// Get entity messages field information
+	entityFields := parser.GetEntityFields(file, registry)
+
+	// Get required/optional fields from request messages
+	requiredFieldsMap, optionalFieldsMap := parser.GetCreateRequestFields(file)
+
+	// Get optional entity fields
+	optionalEntityFieldsMap := parser.GetEntityOptionalFields(file)
+
+	// Get optional update fields
+	optionalUpdateFieldsMap := parser.GetUpdateRequestFields(file)
+
+	// List responses able to return a next page token
+	pageTokenResponses := parser.GetPageTokenResponses(file)
+
+	// Get table names ((grpcgen.table) or the entity name)
+	tableNames := parser.GetEntityTableNames(file)
+
+	// Create directories
+	serviceDir := filepath.Join("src", "service", protoName)
+	handlerDir := filepath.Join(serviceDir, "handler")
+	certsDir := filepath.Join(serviceDir, "certs")
+	logDir := filepath.Join(serviceDir, "log")
+	if !Previewing() {
+		os.MkdirAll(handlerDir, 0755)
+		os.MkdirAll(certsDir, 0755)
+		os.MkdirAll(logDir, 0755)
+	}
+
+	// Check every file this run owns before writing any of them
+	generated := []string{
+		filepath.Join(serviceDir, "main_gen.go"),
+		filepath.Join(handlerDir, "handler_gen.go"),
+		filepath.Join(handlerDir, "handler_gen_test.go"),
+		filepath.Join(serviceDir, "Dockerfile"),
+		filepath.Join(serviceDir, "docker-compose.yml"),
+		filepath.Join(serviceDir, ".gitignore"),
+		filepath.Join(serviceDir, protoName+"_gen.env"),
+	}
+	legacy := map[string]string{
+		filepath.Join(serviceDir, "main.go"):         "func main()",
+		filepath.Join(handlerDir, "handler.go"):      "func NewHandler(",
+		filepath.Join(handlerDir, "handler_test.go"): "func newTestHandler(",
+	}
+	for entityName, methods := range entityMethods {
+		if !parser.IsCRUDEntity(methods) {
+			continue
+		}
+		base := filepath.Join(handlerDir, strings.ToLower(entityName))
+		generated = append(generated, base+"_gen.go", base+"_gen_test.go")
+		legacy[base+".go"] = "func (h *Handler) Create" + entityName + "("
+		legacy[base+"_test.go"] = "func Test" + entityName + "CreateGet("
+	}
+	if err := Preflight(generated, legacy); err != nil {
+		log.Fatal(err)
+	}
+
+	data := settings
+	data.PackagePath = packagePath
+	data.ModulePath = modulePath
+
+	// Generate migrations/<service>/embed.go (embedded into the service binary)
+	if created, err := EnsureMigrationsPackage(protoName); err != nil {
+		log.Fatalf("Failed to create migrations package: %v", err)
+	} else if created != "" {
+		log.Printf("Generated %s\n", created)
+	}
+
+	// Generate main_gen.go (and hooks.go on the first run)
+	if err := GenerateMain(serviceDir, data); err != nil {
+		log.Fatal(err)
+	}
+
+	// Generate handler/handler_gen.go and the shared test helpers
+	handlerData := types.HandlerData{
+		PackagePath: packagePath,
+		ServiceName: serviceName,
+		ModulePath:  modulePath,
+	}
+	if err := GenerateHandlerRoot(handlerDir, handlerData); err != nil {
+		log.Fatal(err)
+	}
+	if err := GenerateHandlerTestRoot(handlerDir, handlerData); err != nil {
+		log.Fatal(err)
+	}
+
+	// The generated tests create the tables the way gen-migration does, in SQLite
+	testSchemas, err := sqliteSchemas(protoName, modulePath)
+	if err != nil {
+		log.Fatalf("Failed to build test schema: %v", err)
+	}
+
+	// Generate CRUD handler files for each entity
+	for entityName, methods := range entityMethods {
+		// Collect Go packages of imported types used by this entity
+		goPackages := []string{}
+		for _, method := range methods {
+			goPackages = append(goPackages, method.GoPackages...)
+		}
+
+		if parser.IsCRUDEntity(methods) {
+			for _, field := range entityFields[entityName] {
+				if field.IsEnum {
+					goPackages = append(goPackages, field.GoPackage)
+				}
+			}
+			// timestamppb is always imported by the CRUD template
+			imports := registry.Imports(goPackages, "google.golang.org/protobuf/types/known/timestamppb")
+
+			tableName := tableNames[entityName]
+			if tableName == "" {
+				tableName = entityName
+			}
+
+			// Generate handler/<entity>_gen.go, its test and the hooks file
+			if err := GenerateCRUDHandler(handlerDir, packagePath, entityName, tableName, testSchemas[entityName], methods, entityFields[entityName], imports, requiredFieldsMap, optionalFieldsMap, optionalEntityFieldsMap, optionalUpdateFieldsMap, pageTokenResponses, modulePath); err != nil {
+				log.Fatal(err)
+			}
+		} else {
+			// Generate simple entity handler stubs (first run only)
+			GenerateEntityHandler(handlerDir, types.EntityHandlerData{
+				PackagePath: packagePath,
+				EntityName:  entityName,
+				Methods:     methods,
+				Imports:     registry.Imports(goPackages),
+			})
+		}
+	}
+
+	// Generate service-level env files (settings from grpc-gen.yaml, and
+	// credentials on the first run)
+	if err := GenerateServiceEnvFile(protoName, data); err != nil {
+		log.Fatal(err)
+	}
+
+	// Generate Dockerfile
+	if err := GenerateDockerfile(protoName, data); err != nil {
+		log.Fatal(err)
+	}
+
+	// Generate docker-compose.yml
+	if err := GenerateDockerCompose(protoName, data); err != nil {
+		log.Fatal(err)
+	}
+
+	// Generate .gitignore
+	if err := GenerateGitignore(protoName); err != nil {
+		log.Fatal(err)
+	}
+
+	if Previewing() {
+		fmt.Println(PreviewSummary())
+		return
+	}
+	log.Printf("Generated skeleton for %s service\n", serviceName)
+}
+
+// sqliteSchemas returns the SQLite CREATE TABLE statements of the CRUD
+// entities of a service, keyed by entity name
+func sqliteSchemas(protoName, modulePath string) (map[string]string, error) {
+	tables, err := migration.LoadTables(protoName, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	sqlite, err := migration.GetDialect("sqlite")
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make(map[string]string)
+	for _, table := range tables {
+		schemas[table.Entity] = sqlite.CreateTable(table)
+	}
+	return schemas, nil
+}
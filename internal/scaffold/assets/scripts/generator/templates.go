@@ -0,0 +1,22 @@
+package generator
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"text/template"
+)
+
+// Templates is where the *.tmpl files are read from: the project's template/
+// directory by default. grpc-gen generate serves the templates embedded in the
+// CLI, overridden by the files of the project's template/ directory.
+var Templates fs.FS = os.DirFS("template")
+
+// parseTemplate parses the template name from Templates with funcs (may be nil)
+func parseTemplate(name string, funcs template.FuncMap) *template.Template {
+	tmpl, err := template.New(name).Funcs(funcs).ParseFS(Templates, name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return tmpl
+}
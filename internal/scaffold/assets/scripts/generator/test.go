@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/types"
+)
+
+// GenerateHandlerTestRoot creates handler/handler_gen_test.go with the helpers
+// shared by the generated CRUD handler tests
+func GenerateHandlerTestRoot(handlerDir string, data types.HandlerData) error {
+	tmpl := parseTemplate("handler_test.tmpl", nil)
+
+	return writeGenerated(filepath.Join(handlerDir, "handler_gen_test.go"), tmpl, data)
+}
+
+// generateCRUDHandlerTest creates handler/<entity>_gen_test.go, which runs the
+// CRUD handler against an in-memory SQLite database created with schema
+func generateCRUDHandlerTest(handlerDir string, handler types.CRUDHandlerData, schema string, funcMap template.FuncMap) error {
+	data := types.CRUDTestData{
+		CRUDHandlerData: handler,
+		Schema:          schema,
+	}
+
+	for _, method := range handler.Methods {
+		switch {
+		case strings.HasPrefix(method.Name, "Create"):
+			data.CreateMethod = method
+		case strings.HasPrefix(method.Name, "Update"):
+			data.UpdateMethod = method
+		case strings.HasPrefix(method.Name, "Delete"):
+			data.DeleteMethod = method
+		case strings.HasPrefix(method.Name, "List"):
+			data.ListMethod = method
+		}
+	}
+
+	optionalUpdate := make(map[string]bool)
+	for _, name := range handler.OptionalUpdateFields {
+		optionalUpdate[name] = true
+	}
+
+	usedAliases := make(map[string]bool)
+	for _, field := range handler.CreateFields {
+		testField, ok := newTestField(field)
+		if !ok {
+			continue
+		}
+		testField.IsUpdateOptional = optionalUpdate[field.ProtoName]
+		if testField.IsUpdateOptional {
+			data.HasOptionalUpdate = true
+		}
+		data.Fields = append(data.Fields, testField)
+
+		if field.IsFilterable && field.Type != "bytes" && testField.LowFilter != testField.HighFilter {
+			data.FilterFields = append(data.FilterFields, testField)
+			if field.FilterKind() == "helper.StringValue" {
+				data.HasTextFilter = true
+			}
+		}
+		if field.IsSortable && field.Type != "bytes" && testField.LowFilter != testField.HighFilter {
+			data.SortFields = append(data.SortFields, testField)
+		}
+		if field.IsEnum {
+			usedAliases[strings.SplitN(field.EnumConstPrefix, ".", 2)[0]] = true
+		}
+	}
+
+	for _, imp := range handler.Imports {
+		if usedAliases[imp.Alias] && imp.Alias != "pb" {
+			data.TestImports = append(data.TestImports, imp)
+		}
+	}
+
+	tmpl := parseTemplate("crud_handler_test.tmpl", funcMap)
+
+	filename := strings.ToLower(handler.EntityName) + "_gen_test.go"
+	return writeGenerated(filepath.Join(handlerDir, filename), tmpl, data)
+}
+
+// newTestField picks the two test values of a field; ok is false for types
+// the CRUD handler does not store
+func newTestField(field types.Field) (types.TestField, bool) {
+	tf := types.TestField{Field: field}
+
+	if field.IsEnum {
+		if len(field.EnumValues) == 0 {
+			return tf, false
+		}
+		low, high := field.EnumValues[0], field.EnumValues[len(field.EnumValues)-1]
+		if field.EnumDBValue(high) < field.EnumDBValue(low) {
+			low, high = high, low
+		}
+		tf.Low, tf.High = field.EnumConstPrefix+low, field.EnumConstPrefix+high
+		tf.LowFilter, tf.HighFilter = field.EnumDBValue(low), field.EnumDBValue(high)
+	} else {
+		switch field.Type {
+		case "string":
+			tf.LowFilter, tf.HighFilter = field.ProtoName+"-a", field.ProtoName+"-b"
+			tf.Low, tf.High = fmt.Sprintf("%q", tf.LowFilter), fmt.Sprintf("%q", tf.HighFilter)
+		case "int32", "int64", "uint32", "uint64":
+			tf.Low, tf.High = field.Type+"(1)", field.Type+"(2)"
+			tf.LowFilter, tf.HighFilter = "1", "2"
+		case "float", "double":
+			goType := map[string]string{"float": "float32", "double": "float64"}[field.Type]
+			tf.Low, tf.High = goType+"(1.5)", goType+"(2.5)"
+			tf.LowFilter, tf.HighFilter = "1.5", "2.5"
+		case "bool":
+			tf.Low, tf.High = "false", "true"
+			tf.LowFilter, tf.HighFilter = "0", "1"
+		case "bytes":
+			tf.Low, tf.High = `[]byte("a")`, `[]byte("b")`
+			tf.LowFilter, tf.HighFilter = "a", "b"
+		default:
+			return tf, false
+		}
+	}
+
+	return tf, true
+}
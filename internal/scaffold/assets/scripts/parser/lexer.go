@@ -0,0 +1,279 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/types"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenInt
+	tokenFloat
+	tokenString
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string // raw text; for strings the unquoted value
+	pos  types.Position
+	// comment collected directly above this token (blank line resets it)
+	leadingComment string
+}
+
+// Error is a parse error with the location of the offending token
+type Error struct {
+	Filename string
+	Line     int
+	Column   int
+	Msg      string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.Filename, e.Line, e.Column, e.Msg)
+}
+
+// lexer splits proto source into tokens and keeps comments so they can be
+// attached to the declarations that follow them
+type lexer struct {
+	filename string
+	src      []rune
+	offset   int
+	line     int
+	col      int
+
+	// trailing comments keyed by the line they appear on
+	trailing map[int]string
+}
+
+func newLexer(filename string, src []byte) *lexer {
+	return &lexer{
+		filename: filename,
+		src:      []rune(string(src)),
+		line:     1,
+		col:      1,
+		trailing: make(map[int]string),
+	}
+}
+
+func (l *lexer) errorf(pos types.Position, format string, args ...interface{}) error {
+	return &Error{Filename: l.filename, Line: pos.Line, Column: pos.Column, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (l *lexer) peekRune(n int) rune {
+	if l.offset+n >= len(l.src) {
+		return 0
+	}
+	return l.src[l.offset+n]
+}
+
+func (l *lexer) advance() rune {
+	r := l.src[l.offset]
+	l.offset++
+	if r == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	return r
+}
+
+// tokenize returns every token of the file followed by an EOF token
+func (l *lexer) tokenize() ([]token, error) {
+	var tokens []token
+	var comments []string
+	lastTokenLine := 0
+
+	for {
+		// Skip whitespace; a blank line detaches pending comments
+		newlines := 0
+		for l.offset < len(l.src) {
+			r := l.peekRune(0)
+			if r == '\n' {
+				newlines++
+				if newlines > 1 {
+					comments = nil
+				}
+			} else if r != ' ' && r != '\t' && r != '\r' && r != '\f' && r != '\v' {
+				break
+			}
+			l.advance()
+		}
+
+		if l.offset >= len(l.src) {
+			tokens = append(tokens, token{kind: tokenEOF, pos: types.Position{Line: l.line, Column: l.col}})
+			return tokens, nil
+		}
+
+		pos := types.Position{Line: l.line, Column: l.col}
+		r := l.peekRune(0)
+
+		// Comments
+		if r == '/' && (l.peekRune(1) == '/' || l.peekRune(1) == '*') {
+			text, err := l.readComment()
+			if err != nil {
+				return nil, err
+			}
+			if pos.Line == lastTokenLine {
+				l.trailing[pos.Line] = text
+			} else {
+				comments = append(comments, text)
+			}
+			continue
+		}
+
+		tok, err := l.readToken(pos)
+		if err != nil {
+			return nil, err
+		}
+		tok.leadingComment = strings.Join(comments, "\n")
+		comments = nil
+		lastTokenLine = l.line
+		tokens = append(tokens, tok)
+	}
+}
+
+func (l *lexer) readComment() (string, error) {
+	start := types.Position{Line: l.line, Column: l.col}
+	l.advance()
+	if l.advance() == '/' {
+		var sb strings.Builder
+		for l.offset < len(l.src) && l.peekRune(0) != '\n' {
+			sb.WriteRune(l.advance())
+		}
+		return strings.TrimSpace(sb.String()), nil
+	}
+
+	var sb strings.Builder
+	for {
+		if l.offset >= len(l.src) {
+			return "", l.errorf(start, "unterminated block comment")
+		}
+		if l.peekRune(0) == '*' && l.peekRune(1) == '/' {
+			l.advance()
+			l.advance()
+			break
+		}
+		sb.WriteRune(l.advance())
+	}
+
+	// Strip leading "*" decorations from block comment lines
+	lines := strings.Split(sb.String(), "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		lines[i] = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
+
+func (l *lexer) readToken(pos types.Position) (token, error) {
+	r := l.peekRune(0)
+
+	switch {
+	case isLetter(r):
+		var sb strings.Builder
+		for l.offset < len(l.src) && (isLetter(l.peekRune(0)) || isDigit(l.peekRune(0))) {
+			sb.WriteRune(l.advance())
+		}
+		return token{kind: tokenIdent, text: sb.String(), pos: pos}, nil
+
+	case isDigit(r) || (r == '.' && isDigit(l.peekRune(1))):
+		return l.readNumber(pos)
+
+	case r == '"' || r == '\'':
+		return l.readString(pos)
+
+	case strings.ContainsRune("{}()[]<>;=,.:-+/", r):
+		l.advance()
+		return token{kind: tokenSymbol, text: string(r), pos: pos}, nil
+	}
+
+	return token{}, l.errorf(pos, "unexpected character %q", r)
+}
+
+func (l *lexer) readNumber(pos types.Position) (token, error) {
+	var sb strings.Builder
+	kind := tokenInt
+
+	if l.peekRune(0) == '0' && (l.peekRune(1) == 'x' || l.peekRune(1) == 'X') {
+		sb.WriteRune(l.advance())
+		sb.WriteRune(l.advance())
+		for l.offset < len(l.src) && isHexDigit(l.peekRune(0)) {
+			sb.WriteRune(l.advance())
+		}
+		return token{kind: kind, text: sb.String(), pos: pos}, nil
+	}
+
+	for l.offset < len(l.src) {
+		r := l.peekRune(0)
+		switch {
+		case isDigit(r):
+			sb.WriteRune(l.advance())
+		case r == '.':
+			kind = tokenFloat
+			sb.WriteRune(l.advance())
+		case r == 'e' || r == 'E':
+			kind = tokenFloat
+			sb.WriteRune(l.advance())
+			if l.peekRune(0) == '+' || l.peekRune(0) == '-' {
+				sb.WriteRune(l.advance())
+			}
+		default:
+			if isLetter(r) {
+				return token{}, l.errorf(pos, "invalid number %q", sb.String()+string(r))
+			}
+			return token{kind: kind, text: sb.String(), pos: pos}, nil
+		}
+	}
+	return token{kind: kind, text: sb.String(), pos: pos}, nil
+}
+
+func (l *lexer) readString(pos types.Position) (token, error) {
+	quote := l.advance()
+	var sb strings.Builder
+	for {
+		if l.offset >= len(l.src) || l.peekRune(0) == '\n' {
+			return token{}, l.errorf(pos, "unterminated string literal")
+		}
+		r := l.advance()
+		if r == quote {
+			break
+		}
+		if r == '\\' && l.offset < len(l.src) {
+			esc := l.advance()
+			switch esc {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case 'r':
+				sb.WriteRune('\r')
+			case '0':
+				sb.WriteRune(0)
+			default:
+				sb.WriteRune(esc)
+			}
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return token{kind: tokenString, text: sb.String(), pos: pos}, nil
+}
+
+func isLetter(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isHexDigit(r rune) bool {
+	return isDigit(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
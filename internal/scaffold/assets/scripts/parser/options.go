@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/types"
+)
+
+// Custom options declared in proto/grpcgen/options.proto
+const (
+	OptionTable      = "(grpcgen.table)"
+	OptionColumn     = "(grpcgen.column)"
+	OptionSkipDB     = "(grpcgen.skip_db)"
+	OptionFilterable = "(grpcgen.filterable)"
+	OptionSortable   = "(grpcgen.sortable)"
+	OptionIndex      = "(grpcgen.index)"
+)
+
+// identRegex matches names that are safe to interpolate into SQL as identifiers
+var identRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// boolOption returns the value of a boolean option, or def when it is not set
+func boolOption(options []types.ProtoOption, name string, def bool) bool {
+	for _, opt := range options {
+		if opt.Name == name {
+			return opt.Value == "true"
+		}
+	}
+	return def
+}
+
+// ValidateOptions checks grpcgen options in entity messages: table and column
+// names must be plain SQL identifiers and boolean options must be true/false
+func ValidateOptions(file *types.ProtoFile) error {
+	invalid := func(pos types.Position, format string, args ...interface{}) error {
+		return &Error{Filename: file.Filename, Line: pos.Line, Column: pos.Column, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	for _, msg := range file.Messages {
+		for _, opt := range msg.Options {
+			if opt.Name == OptionTable && !identRegex.MatchString(opt.Value) {
+				return invalid(opt.Pos, "invalid table name %q in %s", opt.Value, msg.Name)
+			}
+		}
+		for _, field := range msg.Fields {
+			for _, opt := range field.Options {
+				switch opt.Name {
+				case OptionColumn:
+					if !identRegex.MatchString(opt.Value) {
+						return invalid(opt.Pos, "invalid column name %q for %s.%s", opt.Value, msg.Name, field.Name)
+					}
+				case OptionSkipDB, OptionFilterable, OptionSortable, OptionIndex:
+					if opt.Value != "true" && opt.Value != "false" {
+						return invalid(opt.Pos, "%s must be true or false, got %q", opt.Name, opt.Value)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// GetEntityTableNames returns the table name of every entity message:
+// (grpcgen.table) when set, otherwise the message name
+func GetEntityTableNames(file *types.ProtoFile) map[string]string {
+	tables := make(map[string]string)
+	for _, msg := range file.Messages {
+		if !isEntityMessage(msg) {
+			continue
+		}
+		tables[msg.Name] = msg.Name
+		if table, ok := msg.Option(OptionTable); ok {
+			tables[msg.Name] = table
+		}
+	}
+	return tables
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const optionsProto = `syntax = "proto3";
+package thesis;
+
+import "google/protobuf/timestamp.proto";
+
+message Topic {
+  option (grpcgen.table) = "topics";
+  string id = 1;
+  string title = 2 [(grpcgen.column) = "topic_title"];
+  string draft = 3 [(grpcgen.skip_db) = true];
+  string note = 4 [(grpcgen.filterable) = false, (grpcgen.sortable) = false];
+  google.protobuf.Timestamp deadline = 5 [(grpcgen.sortable) = true, (grpcgen.index) = true];
+}
+`
+
+func TestGetEntityFieldsOptions(t *testing.T) {
+	root := writeProtos(t, map[string]string{"proto/thesis/thesis.proto": optionsProto})
+	registry, err := LoadRegistry(root, "proto/thesis/thesis.proto", "example.com/demo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	file := registry.File("proto/thesis/thesis.proto")
+
+	if err := ValidateOptions(file); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if table := GetEntityTableNames(file)["Topic"]; table != "topics" {
+		t.Errorf("table = %q", table)
+	}
+
+	var got []string
+	for _, f := range GetEntityFields(file, registry)["Topic"] {
+		got = append(got, fmt.Sprintf("%s:%s:%t:%t:%t", f.Name, f.DBField, f.IsFilterable, f.IsSortable, f.IsIndexed))
+	}
+	want := "title:topic_title:true:true:false,note:note:false:false:false,deadline:deadline:false:true:true"
+	if strings.Join(got, ",") != want {
+		t.Errorf("fields = %s, want %s", strings.Join(got, ","), want)
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"table", `message Topic { option (grpcgen.table) = "topics; DROP"; }`, `2:17: invalid table name "topics; DROP" in Topic`},
+		{"column", `message Topic { string title = 1 [(grpcgen.column) = "a b"]; }`, `2:35: invalid column name "a b" for Topic.title`},
+		{"bool", `message Topic { string title = 1 [(grpcgen.skip_db) = 1]; }`, `2:35: (grpcgen.skip_db) must be true or false, got "1"`},
+		{"index", `message Topic { string title = 1 [(grpcgen.index) = yes]; }`, `2:35: (grpcgen.index) must be true or false, got "yes"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := ParseProto("x.proto", []byte("syntax = \"proto3\";\n"+tt.src))
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			err = ValidateOptions(file)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
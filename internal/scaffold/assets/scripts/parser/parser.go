@@ -1,330 +1,262 @@
 package parser
 
 import (
-	"bufio"
-	"os"
-	"regexp"
 	"strings"
 
-	"gen_skeleton/types"
-	"gen_skeleton/utils"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/types"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/utils"
 )
 
-// ParseProtoFile extracts RPC methods from proto file
-func ParseProtoFile(filename string) ([]types.Method, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
+// GetMethods extracts unary RPC methods from every service in the file.
+// Streaming RPCs are skipped because the handler templates only produce unary methods.
+func GetMethods(file *types.ProtoFile, registry *Registry) []types.Method {
 	var methods []types.Method
-	scanner := bufio.NewScanner(file)
-	rpcRegex := regexp.MustCompile(`rpc\s+(\w+)\s*\(\s*(\w+)\s*\)\s*returns\s*\(\s*(\w+)\s*\)`)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		matches := rpcRegex.FindStringSubmatch(line)
-		if len(matches) == 4 {
-			methods = append(methods, types.Method{
-				Name:         matches[1],
-				RequestType:  matches[2],
-				ResponseType: matches[3],
-			})
+	for _, svc := range file.Services {
+		for _, rpc := range svc.RPCs {
+			if rpc.ClientStreaming || rpc.ServerStreaming {
+				continue
+			}
+			method := types.Method{
+				Name:           rpc.Name,
+				RequestType:    rpc.RequestType,
+				ResponseType:   rpc.ResponseType,
+				RequestGoType:  "pb." + rpc.RequestType,
+				ResponseGoType: "pb." + rpc.ResponseType,
+			}
+			if info := registry.Resolve(file.Package, rpc.RequestType); info != nil {
+				method.RequestGoType = registry.GoType(info)
+				method.GoPackages = append(method.GoPackages, info.GoPackage)
+			}
+			if info := registry.Resolve(file.Package, rpc.ResponseType); info != nil {
+				method.ResponseGoType = registry.GoType(info)
+				method.GoPackages = append(method.GoPackages, info.GoPackage)
+				if strings.HasPrefix(rpc.Name, "List") && info.Message != nil {
+					method.ListEntity, method.ListField = listEntityField(info, registry)
+				}
+			}
+			methods = append(methods, method)
 		}
 	}
-
-	return methods, scanner.Err()
+	return methods
 }
 
-// ParseEnumsFromProto extracts enum definitions from proto file
-func ParseEnumsFromProto(filename string) (map[string][]string, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
+// listEntityField returns the message type and Go name of the first repeated
+// message field of a List response, e.g. Course and Courses
+func listEntityField(response *TypeInfo, registry *Registry) (string, string) {
+	for _, field := range response.Message.Fields {
+		if field.Label != "repeated" || field.IsMap {
+			continue
+		}
+		if info := registry.Resolve(response.FullName, field.Type); info != nil && info.Message != nil {
+			return info.Message.Name, utils.ToCamelCase(field.Name)
+		}
 	}
-	defer file.Close()
-
-	enums := make(map[string][]string)
-	scanner := bufio.NewScanner(file)
-
-	enumRegex := regexp.MustCompile(`enum\s+(\w+)\s*\{`)
-	enumValueRegex := regexp.MustCompile(`^\s*(\w+)\s*=\s*\d+;`)
+	return "", ""
+}
 
-	var currentEnum string
-	inEnum := false
+// isColumnField reports whether a message field maps to a single column.
+// Repeated, map and oneof fields have no scalar column representation.
+func isColumnField(field *types.ProtoField) bool {
+	return field.Label != "repeated" && !field.IsMap && field.Oneof == ""
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
+// isColumnType reports whether a field of a resolved type is stored in a
+// column: scalars (info is nil), enums and google.protobuf.Timestamp. Other
+// messages have none, so neither the handlers nor the migrations see them.
+func isColumnType(info *TypeInfo) bool {
+	return info == nil || info.IsEnum() || info.FullName == "google.protobuf.Timestamp"
+}
 
-		// Check if starting an enum
-		if matches := enumRegex.FindStringSubmatch(line); len(matches) == 2 {
-			currentEnum = matches[1]
-			inEnum = true
-			enums[currentEnum] = []string{}
+// findMessages returns top-level messages whose name matches prefix + entity + suffix,
+// keyed by the entity part of the name
+func findMessages(file *types.ProtoFile, prefix, suffix string) map[string]*types.ProtoMessage {
+	result := make(map[string]*types.ProtoMessage)
+	for _, msg := range file.Messages {
+		if !strings.HasPrefix(msg.Name, prefix) || !strings.HasSuffix(msg.Name, suffix) {
 			continue
 		}
-
-		// Check if inside enum
-		if inEnum {
-			if strings.Contains(line, "}") {
-				inEnum = false
-				currentEnum = ""
-			} else if matches := enumValueRegex.FindStringSubmatch(line); len(matches) == 2 {
-				enums[currentEnum] = append(enums[currentEnum], matches[1])
-			}
+		entity := strings.TrimSuffix(strings.TrimPrefix(msg.Name, prefix), suffix)
+		if entity != "" {
+			result[entity] = msg
 		}
 	}
-
-	return enums, scanner.Err()
+	return result
 }
 
-// ParseFieldsFromUpdateRequests extracts optional fields from UpdateXRequest messages
-func ParseFieldsFromUpdateRequests(filename string) (map[string][]string, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
+// GetUpdateRequestFields extracts optional fields from UpdateXRequest messages
+func GetUpdateRequestFields(file *types.ProtoFile) map[string][]string {
 	optionalUpdateFields := make(map[string][]string)
-	scanner := bufio.NewScanner(file)
-
-	messageRegex := regexp.MustCompile(`message\s+Update(\w+)Request\s*\{`)
-	fieldRegex := regexp.MustCompile(`^\s*(optional\s+)?(\w+(?:\.\w+\.\w+)?)\s+(\w+)\s*=\s*\d+;`)
-
-	var currentEntity string
-	inMessage := false
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Check if starting an UpdateRequest message
-		if matches := messageRegex.FindStringSubmatch(line); len(matches) == 2 {
-			currentEntity = matches[1]
-			inMessage = true
-			optionalUpdateFields[currentEntity] = []string{}
-			continue
-		}
 
-		// Check if inside message
-		if inMessage {
-			if strings.Contains(line, "}") {
-				inMessage = false
-				currentEntity = ""
-			} else if matches := fieldRegex.FindStringSubmatch(line); len(matches) >= 4 {
-				isOptional := matches[1] != ""
-				fieldName := matches[3]
-
-				// Skip id only (not other fields)
-				if fieldName == "id" {
-					continue
-				}
+	for entity, msg := range findMessages(file, "Update", "Request") {
+		optionalUpdateFields[entity] = []string{}
+		for _, field := range msg.Fields {
+			// Skip id only (not other fields)
+			if !isColumnField(field) || field.Name == "id" {
+				continue
+			}
 
-				// Only track optional fields
-				if isOptional {
-					dbFieldName := utils.ToSnakeCase(fieldName)
-					optionalUpdateFields[currentEntity] = append(optionalUpdateFields[currentEntity], dbFieldName)
-				}
+			// Only track optional fields
+			if field.Label == "optional" {
+				dbFieldName := utils.ToSnakeCase(field.Name)
+				optionalUpdateFields[entity] = append(optionalUpdateFields[entity], dbFieldName)
 			}
 		}
 	}
 
-	return optionalUpdateFields, scanner.Err()
+	return optionalUpdateFields
 }
 
-// ParseFieldsFromCreateRequests extracts required and optional fields from CreateXRequest messages
-func ParseFieldsFromCreateRequests(filename string) (map[string][]string, map[string][]string, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, nil, err
+// GetPageTokenResponses returns the names of the List response messages with
+// a next_page_token field, which generated List handlers fill
+func GetPageTokenResponses(file *types.ProtoFile) map[string]bool {
+	responses := make(map[string]bool)
+	for _, msg := range findMessages(file, "List", "Response") {
+		for _, field := range msg.Fields {
+			if field.Name == "next_page_token" && field.Type == "string" && isColumnField(field) {
+				responses[msg.Name] = true
+			}
+		}
 	}
-	defer file.Close()
+	return responses
+}
 
+// GetCreateRequestFields extracts required and optional fields from CreateXRequest messages
+func GetCreateRequestFields(file *types.ProtoFile) (map[string][]string, map[string][]string) {
 	requiredFields := make(map[string][]string)
 	optionalFields := make(map[string][]string)
-	scanner := bufio.NewScanner(file)
 
-	messageRegex := regexp.MustCompile(`message\s+Create(\w+)Request\s*\{`)
-	fieldRegex := regexp.MustCompile(`^\s*(optional\s+)?(\w+(?:\.\w+\.\w+)?)\s+(\w+)\s*=\s*\d+;`)
-
-	var currentEntity string
-	inMessage := false
-
-	for scanner.Scan() {
-		line := scanner.Text()
+	for entity, msg := range findMessages(file, "Create", "Request") {
+		requiredFields[entity] = []string{}
+		optionalFields[entity] = []string{}
+		for _, field := range msg.Fields {
+			if !isColumnField(field) {
+				continue
+			}
 
-		// Check if starting a CreateRequest message
-		if matches := messageRegex.FindStringSubmatch(line); len(matches) == 2 {
-			currentEntity = matches[1]
-			inMessage = true
-			requiredFields[currentEntity] = []string{}
-			optionalFields[currentEntity] = []string{}
-			continue
-		}
+			// Mark system fields as always optional
+			// but don't skip them - we need to track them
 
-		// Check if inside message
-		if inMessage {
-			if strings.Contains(line, "}") {
-				inMessage = false
-				currentEntity = ""
-			} else if matches := fieldRegex.FindStringSubmatch(line); len(matches) >= 4 {
-				isOptional := matches[1] != ""
-				fieldName := matches[3]
-
-				// Mark system fields as always optional
-				// but don't skip them - we need to track them
-
-				dbFieldName := utils.ToSnakeCase(fieldName)
-				if isOptional {
-					optionalFields[currentEntity] = append(optionalFields[currentEntity], dbFieldName)
-				} else {
-					requiredFields[currentEntity] = append(requiredFields[currentEntity], dbFieldName)
-				}
+			dbFieldName := utils.ToSnakeCase(field.Name)
+			if field.Label == "optional" {
+				optionalFields[entity] = append(optionalFields[entity], dbFieldName)
+			} else {
+				requiredFields[entity] = append(requiredFields[entity], dbFieldName)
 			}
 		}
 	}
 
-	return requiredFields, optionalFields, scanner.Err()
+	return requiredFields, optionalFields
 }
 
-// ParseEntityOptionalFields extracts optional field names from entity messages
-func ParseEntityOptionalFields(filename string) (map[string][]string, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
+// isEntityMessage reports whether a top-level message is an entity (not Request/Response)
+func isEntityMessage(msg *types.ProtoMessage) bool {
+	return !strings.HasSuffix(msg.Name, "Request") && !strings.HasSuffix(msg.Name, "Response")
+}
 
+// GetEntityOptionalFields extracts optional field names from entity messages
+func GetEntityOptionalFields(file *types.ProtoFile) map[string][]string {
 	optionalEntityFields := make(map[string][]string)
-	scanner := bufio.NewScanner(file)
 
-	messageRegex := regexp.MustCompile(`message\s+(\w+)\s*\{`)
-	fieldRegex := regexp.MustCompile(`^\s*(optional\s+)?(\w+(?:\.\w+\.\w+)?)\s+(\w+)\s*=\s*\d+;`)
-
-	var currentEntity string
-	inMessage := false
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Check if starting an entity message (not Request/Response)
-		if matches := messageRegex.FindStringSubmatch(line); len(matches) == 2 {
-			msgName := matches[1]
-			if !strings.HasSuffix(msgName, "Request") && !strings.HasSuffix(msgName, "Response") {
-				currentEntity = msgName
-				inMessage = true
-				optionalEntityFields[currentEntity] = []string{}
-			}
+	for _, msg := range file.Messages {
+		if !isEntityMessage(msg) {
 			continue
 		}
-
-		// Check if inside message
-		if inMessage {
-			if strings.Contains(line, "}") {
-				inMessage = false
-				currentEntity = ""
-			} else if matches := fieldRegex.FindStringSubmatch(line); len(matches) >= 4 {
-				isOptional := matches[1] != ""
-				fieldName := matches[3]
-
-				// Track optional fields (including system fields)
-				if isOptional {
-					dbFieldName := utils.ToSnakeCase(fieldName)
-					optionalEntityFields[currentEntity] = append(optionalEntityFields[currentEntity], dbFieldName)
-				}
+		optionalEntityFields[msg.Name] = []string{}
+		for _, field := range msg.Fields {
+			// Track optional fields (including system fields)
+			if isColumnField(field) && field.Label == "optional" {
+				dbFieldName := utils.ToSnakeCase(field.Name)
+				optionalEntityFields[msg.Name] = append(optionalEntityFields[msg.Name], dbFieldName)
 			}
 		}
 	}
 
-	return optionalEntityFields, scanner.Err()
+	return optionalEntityFields
 }
 
-// ParseEntityFields extracts field information from entity messages
-func ParseEntityFields(filename string, enums map[string][]string) (map[string][]types.Field, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
+// GetEntityFields extracts field information from entity messages.
+// Field types are resolved through the registry, so enums declared in
+// imported files get package-qualified Go constants. Fields marked
+// (grpcgen.skip_db) are left out and (grpcgen.column) overrides DBField.
+func GetEntityFields(file *types.ProtoFile, registry *Registry) map[string][]types.Field {
 	entityFields := make(map[string][]types.Field)
-	scanner := bufio.NewScanner(file)
 
-	messageRegex := regexp.MustCompile(`message\s+(\w+)\s*\{`)
-	fieldRegex := regexp.MustCompile(`^\s*(optional\s+)?(\w+(?:\.\w+\.\w+)?)\s+(\w+)\s*=\s*\d+;`)
+	for _, msg := range file.Messages {
+		// Only track entity messages (not Request/Response)
+		if !isEntityMessage(msg) {
+			continue
+		}
+		entityFields[msg.Name] = []types.Field{}
+		scope := msg.Name
+		if file.Package != "" {
+			scope = file.Package + "." + msg.Name
+		}
 
-	var currentMessage string
-	inMessage := false
+		for _, protoField := range msg.Fields {
+			info := registry.Resolve(scope, protoField.Type)
+			if !isColumnField(protoField) || !isColumnType(info) || boolOption(protoField.Options, OptionSkipDB, false) {
+				continue
+			}
 
-	for scanner.Scan() {
-		line := scanner.Text()
+			fieldType := protoField.Type
+			fieldName := protoField.Name
 
-		// Check if starting a message (entity)
-		if matches := messageRegex.FindStringSubmatch(line); len(matches) == 2 {
-			msgName := matches[1]
-			// Only track entity messages (not Request/Response)
-			if !strings.HasSuffix(msgName, "Request") && !strings.HasSuffix(msgName, "Response") {
-				currentMessage = msgName
-				inMessage = true
-				entityFields[currentMessage] = []types.Field{}
+			// Skip system fields (id, timestamps, created_by, updated_by)
+			// These fields are handled separately
+			if fieldName == "id" || fieldName == "created_at" || fieldName == "updated_at" ||
+				fieldName == "created_by" || fieldName == "updated_by" {
+				continue
 			}
-			continue
-		}
 
-		// Check if inside message
-		if inMessage {
-			if strings.Contains(line, "}") {
-				inMessage = false
-				currentMessage = ""
-			} else if matches := fieldRegex.FindStringSubmatch(line); len(matches) >= 4 {
-				isOptional := matches[1] != ""
-				fieldType := matches[2]
-				fieldName := matches[3]
-
-				// Skip system fields (id, timestamps, created_by, updated_by)
-				// These fields are handled separately
-				if fieldName == "id" || fieldName == "created_at" || fieldName == "updated_at" ||
-					fieldName == "created_by" || fieldName == "updated_by" {
-					continue
-				}
+			field := types.Field{
+				Name:       fieldName,
+				ProtoName:  utils.ToSnakeCase(fieldName),
+				Type:       fieldType,
+				GoName:     utils.ToCamelCase(fieldName),
+				DBField:    utils.ToSnakeCase(fieldName),
+				IsOptional: protoField.Label == "optional",
+			}
+			if column, ok := protoField.Option(OptionColumn); ok {
+				field.DBField = column
+			}
 
-				field := types.Field{
-					Name:       fieldName,
-					ProtoName:  utils.ToSnakeCase(fieldName),
-					Type:       fieldType,
-					GoName:     utils.ToCamelCase(fieldName),
-					DBField:    utils.ToSnakeCase(fieldName),
-					IsOptional: isOptional,
+			// Check if enum
+			if info != nil && info.IsEnum() {
+				field.IsEnum = true
+				field.EnumType = fieldType
+				field.EnumConstPrefix = registry.Alias(info.GoPackage) + "." + info.ConstPrefix
+				field.EnumValuePrefix = strings.ToUpper(utils.ToSnakeCase(info.Enum.Name)) + "_"
+				field.GoPackage = info.GoPackage
+				field.EnumValues = []string{}
+				for _, value := range info.Enum.Values {
+					field.EnumValues = append(field.EnumValues, value.Name)
 				}
-
-				// Check if enum
-				if _, isEnum := enums[fieldType]; isEnum {
-					field.IsEnum = true
-					field.EnumType = fieldType
-					field.EnumValues = enums[fieldType]
-					if len(field.EnumValues) > 0 {
-						field.DefaultValue = field.EnumValues[0]
-						field.DefaultDBValue = strings.ToLower(field.EnumValues[0])
-					}
-				} else if fieldType == "string" {
-					field.DefaultValue = `""`
-				} else if fieldType == "int32" {
-					field.DefaultValue = "int32(0)"
-				} else if fieldType == "int64" {
-					field.DefaultValue = "int64(0)"
-				} else if fieldType == "bool" {
-					field.DefaultValue = "false"
-				} else if strings.Contains(fieldType, "Timestamp") {
-					field.IsTimestamp = true
+				if len(field.EnumValues) > 0 {
+					field.DefaultValue = field.EnumValues[0]
+					field.DefaultDBValue = field.EnumDBValue(field.EnumValues[0])
 				}
-
-				entityFields[currentMessage] = append(entityFields[currentMessage], field)
+			} else if fieldType == "string" {
+				field.DefaultValue = `""`
+			} else if fieldType == "int32" {
+				field.DefaultValue = "int32(0)"
+			} else if fieldType == "int64" {
+				field.DefaultValue = "int64(0)"
+			} else if fieldType == "bool" {
+				field.DefaultValue = "false"
+			} else if info != nil && info.FullName == "google.protobuf.Timestamp" {
+				field.IsTimestamp = true
 			}
+
+			// Timestamps are not filterable or sortable unless explicitly enabled
+			field.IsFilterable = boolOption(protoField.Options, OptionFilterable, !field.IsTimestamp)
+			field.IsSortable = boolOption(protoField.Options, OptionSortable, !field.IsTimestamp)
+			field.IsIndexed = boolOption(protoField.Options, OptionIndex, false)
+
+			entityFields[msg.Name] = append(entityFields[msg.Name], field)
 		}
 	}
 
-	return entityFields, scanner.Err()
+	return entityFields
 }
 
 // GroupMethodsByEntity groups RPC methods by their entity name
@@ -333,6 +265,7 @@ func GroupMethodsByEntity(methods []types.Method) map[string][]types.Method {
 
 	// First pass: get entity names from Create methods (canonical names)
 	entityNames := make(map[string]string) // normalized -> canonical
+	canonicalNames := make(map[string]string)
 	for _, method := range methods {
 		if strings.HasPrefix(method.Name, "Create") {
 			canonicalName := strings.TrimPrefix(method.Name, "Create")
@@ -341,6 +274,7 @@ func GroupMethodsByEntity(methods []types.Method) map[string][]types.Method {
 			// Normalize for matching
 			normalized := utils.NormalizePlural(canonicalName)
 			entityNames[normalized] = canonicalName
+			canonicalNames[canonicalName] = canonicalName
 		}
 	}
 
@@ -355,6 +289,13 @@ func GroupMethodsByEntity(methods []types.Method) map[string][]types.Method {
 			}
 		}
 
+		// List rpcs belong to the entity their response lists, whatever
+		// the plural in the rpc name
+		if canonical, ok := canonicalNames[method.ListEntity]; ok && strings.HasPrefix(method.Name, "List") {
+			entityMethods[canonical] = append(entityMethods[canonical], method)
+			continue
+		}
+
 		// Normalize to find canonical name
 		normalized := utils.NormalizePlural(entityName)
 
@@ -0,0 +1,767 @@
+package parser
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/types"
+)
+
+// ParseProtoFile reads and parses a .proto file into the descriptor model
+func ParseProtoFile(filename string) (*types.ProtoFile, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseProto(filename, src)
+}
+
+// ParseProto parses proto source into the descriptor model.
+// Errors are reported as *Error with the line and column of the offending token.
+func ParseProto(filename string, src []byte) (*types.ProtoFile, error) {
+	lex := newLexer(filename, src)
+	tokens, err := lex.tokenize()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &protoParser{lex: lex, tokens: tokens}
+	return p.parseFile()
+}
+
+type protoParser struct {
+	lex    *lexer
+	tokens []token
+	pos    int
+}
+
+func (p *protoParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *protoParser) peekAt(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *protoParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *protoParser) errorf(tok token, format string, args ...interface{}) error {
+	return p.lex.errorf(tok.pos, format, args...)
+}
+
+func describe(tok token) string {
+	switch tok.kind {
+	case tokenEOF:
+		return "end of file"
+	case tokenString:
+		return strconv.Quote(tok.text)
+	default:
+		return "'" + tok.text + "'"
+	}
+}
+
+func (p *protoParser) isSymbol(sym string) bool {
+	tok := p.peek()
+	return tok.kind == tokenSymbol && tok.text == sym
+}
+
+func (p *protoParser) isKeyword(word string) bool {
+	tok := p.peek()
+	return tok.kind == tokenIdent && tok.text == word
+}
+
+func (p *protoParser) expectSymbol(sym string) (token, error) {
+	tok := p.next()
+	if tok.kind != tokenSymbol || tok.text != sym {
+		return tok, p.errorf(tok, "expected '%s' but found %s", sym, describe(tok))
+	}
+	return tok, nil
+}
+
+func (p *protoParser) expectIdent(what string) (token, error) {
+	tok := p.next()
+	if tok.kind != tokenIdent {
+		return tok, p.errorf(tok, "expected %s but found %s", what, describe(tok))
+	}
+	return tok, nil
+}
+
+func (p *protoParser) expectString(what string) (token, error) {
+	tok := p.next()
+	if tok.kind != tokenString {
+		return tok, p.errorf(tok, "expected %s but found %s", what, describe(tok))
+	}
+	// Adjacent string literals are concatenated
+	for p.peek().kind == tokenString {
+		tok.text += p.next().text
+	}
+	return tok, nil
+}
+
+// parseFullIdent parses a dotted name such as google.protobuf.Timestamp or .pkg.Type
+func (p *protoParser) parseFullIdent(what string) (string, token, error) {
+	first := p.peek()
+	var sb strings.Builder
+	if p.isSymbol(".") {
+		p.next()
+		sb.WriteString(".")
+	}
+	tok, err := p.expectIdent(what)
+	if err != nil {
+		return "", first, err
+	}
+	sb.WriteString(tok.text)
+	for p.isSymbol(".") {
+		p.next()
+		tok, err := p.expectIdent(what)
+		if err != nil {
+			return "", first, err
+		}
+		sb.WriteString(".")
+		sb.WriteString(tok.text)
+	}
+	return sb.String(), first, nil
+}
+
+// endStatement consumes the terminating ';' and returns the trailing comment on that line
+func (p *protoParser) endStatement() (string, error) {
+	tok, err := p.expectSymbol(";")
+	if err != nil {
+		return "", err
+	}
+	return p.lex.trailing[tok.pos.Line], nil
+}
+
+// skipEmptyStatements consumes stray ';' tokens, which proto allows
+func (p *protoParser) skipEmptyStatements() {
+	for p.isSymbol(";") {
+		p.next()
+	}
+}
+
+func (p *protoParser) parseFile() (*types.ProtoFile, error) {
+	file := &types.ProtoFile{Filename: p.lex.filename, Syntax: "proto2"}
+
+	for {
+		p.skipEmptyStatements()
+		tok := p.peek()
+		if tok.kind == tokenEOF {
+			return file, nil
+		}
+		if tok.kind != tokenIdent {
+			return nil, p.errorf(tok, "expected top-level declaration but found %s", describe(tok))
+		}
+
+		var err error
+		switch tok.text {
+		case "syntax", "edition":
+			p.next()
+			if _, err = p.expectSymbol("="); err != nil {
+				return nil, err
+			}
+			var value token
+			if value, err = p.expectString(tok.text + " value"); err != nil {
+				return nil, err
+			}
+			file.Syntax = value.text
+			_, err = p.endStatement()
+		case "package":
+			p.next()
+			if file.Package, _, err = p.parseFullIdent("package name"); err != nil {
+				return nil, err
+			}
+			_, err = p.endStatement()
+		case "import":
+			err = p.parseImport(file)
+		case "option":
+			var opt types.ProtoOption
+			if opt, err = p.parseOptionStatement(); err == nil {
+				file.Options = append(file.Options, opt)
+			}
+		case "message":
+			var msg *types.ProtoMessage
+			if msg, err = p.parseMessage(); err == nil {
+				file.Messages = append(file.Messages, msg)
+			}
+		case "enum":
+			var enum *types.ProtoEnum
+			if enum, err = p.parseEnum(); err == nil {
+				file.Enums = append(file.Enums, enum)
+			}
+		case "service":
+			var svc *types.ProtoService
+			if svc, err = p.parseService(); err == nil {
+				file.Services = append(file.Services, svc)
+			}
+		case "extend":
+			var ext *types.ProtoExtend
+			if ext, err = p.parseExtend(); err == nil {
+				file.Extends = append(file.Extends, ext)
+			}
+		default:
+			return nil, p.errorf(tok, "unexpected %s at top level", describe(tok))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *protoParser) parseImport(file *types.ProtoFile) error {
+	start := p.next()
+	imp := types.ProtoImport{Pos: start.pos}
+	if p.isKeyword("public") || p.isKeyword("weak") {
+		imp.Modifier = p.next().text
+	}
+	path, err := p.expectString("import path")
+	if err != nil {
+		return err
+	}
+	imp.Path = path.text
+	if _, err := p.endStatement(); err != nil {
+		return err
+	}
+	file.Imports = append(file.Imports, imp)
+	return nil
+}
+
+// parseOptionName parses `name`, `(custom.name)` or `(custom.name).sub`
+func (p *protoParser) parseOptionName() (string, error) {
+	var sb strings.Builder
+	for {
+		if p.isSymbol("(") {
+			p.next()
+			name, _, err := p.parseFullIdent("option name")
+			if err != nil {
+				return "", err
+			}
+			if _, err := p.expectSymbol(")"); err != nil {
+				return "", err
+			}
+			sb.WriteString("(" + name + ")")
+		} else {
+			tok, err := p.expectIdent("option name")
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(tok.text)
+		}
+		if !p.isSymbol(".") {
+			return sb.String(), nil
+		}
+		p.next()
+		sb.WriteString(".")
+	}
+}
+
+// parseOptionValue parses a constant: identifier, number, string or aggregate
+func (p *protoParser) parseOptionValue() (string, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == tokenString:
+		value, err := p.expectString("option value")
+		return value.text, err
+	case tok.kind == tokenInt || tok.kind == tokenFloat:
+		return p.next().text, nil
+	case tok.kind == tokenIdent:
+		value, _, err := p.parseFullIdent("option value")
+		return value, err
+	case p.isSymbol("-") || p.isSymbol("+"):
+		sign := p.next().text
+		num := p.next()
+		if num.kind != tokenInt && num.kind != tokenFloat && num.kind != tokenIdent {
+			return "", p.errorf(num, "expected number after '%s' but found %s", sign, describe(num))
+		}
+		if sign == "+" {
+			sign = ""
+		}
+		return sign + num.text, nil
+	case p.isSymbol("{"):
+		return p.parseAggregate()
+	}
+	return "", p.errorf(tok, "expected option value but found %s", describe(tok))
+}
+
+// parseAggregate consumes a text-format message literal and returns it verbatim
+func (p *protoParser) parseAggregate() (string, error) {
+	open := p.next()
+	depth := 1
+	var parts []string
+	for depth > 0 {
+		tok := p.next()
+		switch {
+		case tok.kind == tokenEOF:
+			return "", p.errorf(open, "unterminated aggregate option value")
+		case tok.kind == tokenSymbol && tok.text == "{":
+			depth++
+		case tok.kind == tokenSymbol && tok.text == "}":
+			depth--
+			if depth == 0 {
+				continue
+			}
+		}
+		if tok.kind == tokenString {
+			parts = append(parts, strconv.Quote(tok.text))
+		} else {
+			parts = append(parts, tok.text)
+		}
+	}
+	return "{" + strings.Join(parts, " ") + "}", nil
+}
+
+func (p *protoParser) parseOptionStatement() (types.ProtoOption, error) {
+	start := p.next()
+	name, err := p.parseOptionName()
+	if err != nil {
+		return types.ProtoOption{}, err
+	}
+	if _, err := p.expectSymbol("="); err != nil {
+		return types.ProtoOption{}, err
+	}
+	value, err := p.parseOptionValue()
+	if err != nil {
+		return types.ProtoOption{}, err
+	}
+	if _, err := p.endStatement(); err != nil {
+		return types.ProtoOption{}, err
+	}
+	return types.ProtoOption{Name: name, Value: value, Pos: start.pos}, nil
+}
+
+// parseCompactOptions parses `[name = value, ...]` after a field or enum value
+func (p *protoParser) parseCompactOptions() ([]types.ProtoOption, error) {
+	if !p.isSymbol("[") {
+		return nil, nil
+	}
+	p.next()
+	var options []types.ProtoOption
+	for {
+		start := p.peek()
+		name, err := p.parseOptionName()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expectSymbol("="); err != nil {
+			return nil, err
+		}
+		value, err := p.parseOptionValue()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, types.ProtoOption{Name: name, Value: value, Pos: start.pos})
+		if p.isSymbol(",") {
+			p.next()
+			continue
+		}
+		if _, err := p.expectSymbol("]"); err != nil {
+			return nil, err
+		}
+		return options, nil
+	}
+}
+
+// skipRanges consumes the body of `reserved` and `extensions` statements
+func (p *protoParser) skipRanges() error {
+	p.next()
+	for !p.isSymbol(";") {
+		tok := p.next()
+		if tok.kind == tokenEOF {
+			return p.errorf(tok, "expected ';' but found end of file")
+		}
+		if tok.kind == tokenSymbol && tok.text == "[" {
+			p.pos--
+			if _, err := p.parseCompactOptions(); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := p.endStatement()
+	return err
+}
+
+func (p *protoParser) parseMessage() (*types.ProtoMessage, error) {
+	start := p.next()
+	name, err := p.expectIdent("message name")
+	if err != nil {
+		return nil, err
+	}
+	msg := &types.ProtoMessage{Name: name.text, Comment: start.leadingComment, Pos: start.pos}
+	if _, err := p.expectSymbol("{"); err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipEmptyStatements()
+		tok := p.peek()
+		if tok.kind == tokenSymbol && tok.text == "}" {
+			msg.EndPos = p.next().pos
+			return msg, nil
+		}
+		if tok.kind == tokenEOF {
+			return nil, p.errorf(tok, "expected '}' to close message %s but found end of file", msg.Name)
+		}
+
+		switch {
+		case p.isKeyword("message"):
+			nested, err := p.parseMessage()
+			if err != nil {
+				return nil, err
+			}
+			msg.Messages = append(msg.Messages, nested)
+		case p.isKeyword("enum"):
+			enum, err := p.parseEnum()
+			if err != nil {
+				return nil, err
+			}
+			msg.Enums = append(msg.Enums, enum)
+		case p.isKeyword("option"):
+			opt, err := p.parseOptionStatement()
+			if err != nil {
+				return nil, err
+			}
+			msg.Options = append(msg.Options, opt)
+		case p.isKeyword("oneof"):
+			if err := p.parseOneof(msg); err != nil {
+				return nil, err
+			}
+		case p.isKeyword("reserved") || p.isKeyword("extensions"):
+			if err := p.skipRanges(); err != nil {
+				return nil, err
+			}
+		case p.isKeyword("extend"):
+			if _, err := p.parseExtend(); err != nil {
+				return nil, err
+			}
+		default:
+			field, err := p.parseField("")
+			if err != nil {
+				return nil, err
+			}
+			msg.Fields = append(msg.Fields, field)
+		}
+	}
+}
+
+func (p *protoParser) parseOneof(msg *types.ProtoMessage) error {
+	p.next()
+	name, err := p.expectIdent("oneof name")
+	if err != nil {
+		return err
+	}
+	if _, err := p.expectSymbol("{"); err != nil {
+		return err
+	}
+	msg.Oneofs = append(msg.Oneofs, name.text)
+
+	for {
+		p.skipEmptyStatements()
+		if p.isSymbol("}") {
+			p.next()
+			return nil
+		}
+		if p.peek().kind == tokenEOF {
+			return p.errorf(p.peek(), "expected '}' to close oneof %s but found end of file", name.text)
+		}
+		if p.isKeyword("option") {
+			if _, err := p.parseOptionStatement(); err != nil {
+				return err
+			}
+			continue
+		}
+		field, err := p.parseField(name.text)
+		if err != nil {
+			return err
+		}
+		msg.Fields = append(msg.Fields, field)
+	}
+}
+
+// parseField parses a normal field or a map field
+func (p *protoParser) parseField(oneof string) (*types.ProtoField, error) {
+	start := p.peek()
+	field := &types.ProtoField{Oneof: oneof, Comment: start.leadingComment, Pos: start.pos}
+
+	if oneof == "" && (p.isKeyword("optional") || p.isKeyword("repeated") || p.isKeyword("required")) {
+		// A label is only a label if a type follows it (a field may be *named* optional)
+		if next := p.peekAt(1); next.kind == tokenIdent || (next.kind == tokenSymbol && next.text == ".") {
+			field.Label = p.next().text
+		}
+	}
+
+	if p.isKeyword("map") && p.peekAt(1).kind == tokenSymbol && p.peekAt(1).text == "<" {
+		p.next()
+		p.next()
+		key, _, err := p.parseFullIdent("map key type")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expectSymbol(","); err != nil {
+			return nil, err
+		}
+		value, _, err := p.parseFullIdent("map value type")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expectSymbol(">"); err != nil {
+			return nil, err
+		}
+		field.IsMap = true
+		field.KeyType = key
+		field.ValueType = value
+		field.Type = "map<" + key + ", " + value + ">"
+	} else {
+		typeName, tok, err := p.parseFullIdent("field type")
+		if err != nil {
+			return nil, err
+		}
+		if typeName == "group" {
+			return nil, p.errorf(tok, "groups are not supported")
+		}
+		field.Type = typeName
+	}
+
+	name, err := p.expectIdent("field name")
+	if err != nil {
+		return nil, err
+	}
+	field.Name = name.text
+
+	if _, err := p.expectSymbol("="); err != nil {
+		return nil, err
+	}
+	numTok := p.next()
+	if numTok.kind != tokenInt {
+		return nil, p.errorf(numTok, "expected field number but found %s", describe(numTok))
+	}
+	number, err := strconv.ParseInt(numTok.text, 0, 32)
+	if err != nil || number <= 0 {
+		return nil, p.errorf(numTok, "invalid field number %s", numTok.text)
+	}
+	field.Number = int(number)
+
+	if field.Options, err = p.parseCompactOptions(); err != nil {
+		return nil, err
+	}
+	if field.TrailingComment, err = p.endStatement(); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+func (p *protoParser) parseEnum() (*types.ProtoEnum, error) {
+	start := p.next()
+	name, err := p.expectIdent("enum name")
+	if err != nil {
+		return nil, err
+	}
+	enum := &types.ProtoEnum{Name: name.text, Comment: start.leadingComment, Pos: start.pos}
+	if _, err := p.expectSymbol("{"); err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipEmptyStatements()
+		tok := p.peek()
+		if tok.kind == tokenSymbol && tok.text == "}" {
+			enum.EndPos = p.next().pos
+			return enum, nil
+		}
+		if tok.kind == tokenEOF {
+			return nil, p.errorf(tok, "expected '}' to close enum %s but found end of file", enum.Name)
+		}
+
+		if p.isKeyword("option") && p.peekAt(1).text != "=" {
+			opt, err := p.parseOptionStatement()
+			if err != nil {
+				return nil, err
+			}
+			enum.Options = append(enum.Options, opt)
+			continue
+		}
+		if p.isKeyword("reserved") && p.peekAt(1).text != "=" {
+			if err := p.skipRanges(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		valueName, err := p.expectIdent("enum value name")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expectSymbol("="); err != nil {
+			return nil, err
+		}
+		sign := ""
+		if p.isSymbol("-") {
+			p.next()
+			sign = "-"
+		}
+		numTok := p.next()
+		if numTok.kind != tokenInt {
+			return nil, p.errorf(numTok, "expected enum value number but found %s", describe(numTok))
+		}
+		number, err := strconv.ParseInt(sign+numTok.text, 0, 32)
+		if err != nil {
+			return nil, p.errorf(numTok, "invalid enum value number %s", sign+numTok.text)
+		}
+
+		value := &types.ProtoEnumValue{
+			Name:    valueName.text,
+			Number:  int(number),
+			Comment: valueName.leadingComment,
+			Pos:     valueName.pos,
+		}
+		if value.Options, err = p.parseCompactOptions(); err != nil {
+			return nil, err
+		}
+		if value.TrailingComment, err = p.endStatement(); err != nil {
+			return nil, err
+		}
+		enum.Values = append(enum.Values, value)
+	}
+}
+
+func (p *protoParser) parseService() (*types.ProtoService, error) {
+	start := p.next()
+	name, err := p.expectIdent("service name")
+	if err != nil {
+		return nil, err
+	}
+	svc := &types.ProtoService{Name: name.text, Comment: start.leadingComment, Pos: start.pos}
+	if _, err := p.expectSymbol("{"); err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipEmptyStatements()
+		tok := p.peek()
+		if tok.kind == tokenSymbol && tok.text == "}" {
+			svc.EndPos = p.next().pos
+			return svc, nil
+		}
+		if tok.kind == tokenEOF {
+			return nil, p.errorf(tok, "expected '}' to close service %s but found end of file", svc.Name)
+		}
+
+		switch {
+		case p.isKeyword("option"):
+			opt, err := p.parseOptionStatement()
+			if err != nil {
+				return nil, err
+			}
+			svc.Options = append(svc.Options, opt)
+		case p.isKeyword("rpc"):
+			rpc, err := p.parseRPC()
+			if err != nil {
+				return nil, err
+			}
+			svc.RPCs = append(svc.RPCs, rpc)
+		default:
+			return nil, p.errorf(tok, "expected 'rpc' or 'option' in service %s but found %s", svc.Name, describe(tok))
+		}
+	}
+}
+
+func (p *protoParser) parseRPCType() (string, bool, error) {
+	if _, err := p.expectSymbol("("); err != nil {
+		return "", false, err
+	}
+	streaming := false
+	if p.isKeyword("stream") && p.peekAt(1).text != ")" {
+		p.next()
+		streaming = true
+	}
+	typeName, _, err := p.parseFullIdent("message type")
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := p.expectSymbol(")"); err != nil {
+		return "", false, err
+	}
+	return typeName, streaming, nil
+}
+
+func (p *protoParser) parseRPC() (*types.ProtoRPC, error) {
+	start := p.next()
+	name, err := p.expectIdent("rpc name")
+	if err != nil {
+		return nil, err
+	}
+	rpc := &types.ProtoRPC{Name: name.text, Comment: start.leadingComment, Pos: start.pos}
+
+	if rpc.RequestType, rpc.ClientStreaming, err = p.parseRPCType(); err != nil {
+		return nil, err
+	}
+	returns := p.next()
+	if returns.kind != tokenIdent || returns.text != "returns" {
+		return nil, p.errorf(returns, "expected 'returns' but found %s", describe(returns))
+	}
+	if rpc.ResponseType, rpc.ServerStreaming, err = p.parseRPCType(); err != nil {
+		return nil, err
+	}
+
+	// Either `;` or a body with options
+	if p.isSymbol("{") {
+		p.next()
+		for {
+			p.skipEmptyStatements()
+			if p.isSymbol("}") {
+				close := p.next()
+				rpc.TrailingComment = p.lex.trailing[close.pos.Line]
+				p.skipEmptyStatements()
+				return rpc, nil
+			}
+			if !p.isKeyword("option") {
+				return nil, p.errorf(p.peek(), "expected 'option' or '}' in rpc %s but found %s", rpc.Name, describe(p.peek()))
+			}
+			opt, err := p.parseOptionStatement()
+			if err != nil {
+				return nil, err
+			}
+			rpc.Options = append(rpc.Options, opt)
+		}
+	}
+
+	if rpc.TrailingComment, err = p.endStatement(); err != nil {
+		return nil, err
+	}
+	return rpc, nil
+}
+
+func (p *protoParser) parseExtend() (*types.ProtoExtend, error) {
+	start := p.next()
+	extendee, _, err := p.parseFullIdent("extended message name")
+	if err != nil {
+		return nil, err
+	}
+	ext := &types.ProtoExtend{Extendee: extendee, Pos: start.pos}
+	if _, err := p.expectSymbol("{"); err != nil {
+		return nil, err
+	}
+	for {
+		p.skipEmptyStatements()
+		if p.isSymbol("}") {
+			p.next()
+			return ext, nil
+		}
+		if p.peek().kind == tokenEOF {
+			return nil, p.errorf(p.peek(), "expected '}' to close extend %s but found end of file", extendee)
+		}
+		field, err := p.parseField("")
+		if err != nil {
+			return nil, err
+		}
+		ext.Fields = append(ext.Fields, field)
+	}
+}
@@ -0,0 +1,283 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleProto = `syntax = "proto3";
+
+package thesis;
+
+option go_package = "example.com/demo/proto/thesis";
+
+import "google/protobuf/timestamp.proto";
+import "proto/common/common.proto";
+
+// Topic status
+enum TopicStatus {
+  SUBMIT = 0; // initial state
+  APPROVED = 1;
+}
+
+/* A thesis topic */
+message Topic {
+  string id = 1;
+  string title = 2 [json_name = "topicTitle"]; // shown in lists
+  optional int32 grade = 3;
+  repeated string tags = 4;
+  map<string, int32> scores = 5;
+  TopicStatus status = 6;
+  message Note { string text = 1; }
+  Note note = 7;
+  oneof owner {
+    string student_code = 8;
+    string lecturer_code = 9;
+  }
+  google.protobuf.Timestamp created_at = 10; }
+
+message CreateTopicRequest {
+  string title = 1;
+  optional int32 grade = 2;
+  TopicStatus status = 3;
+}
+
+service ThesisService {
+  rpc CreateTopic(
+      CreateTopicRequest)
+      returns (Topic);
+  rpc WatchTopics(CreateTopicRequest) returns (stream Topic) {}
+}
+`
+
+func TestParseProto(t *testing.T) {
+	file, err := ParseProto("thesis.proto", []byte(sampleProto))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if file.Syntax != "proto3" || file.Package != "thesis" {
+		t.Errorf("syntax/package = %q/%q", file.Syntax, file.Package)
+	}
+	if v, _ := file.Option("go_package"); v != "example.com/demo/proto/thesis" {
+		t.Errorf("go_package = %q", v)
+	}
+	if len(file.Imports) != 2 || file.Imports[1].Path != "proto/common/common.proto" {
+		t.Errorf("imports = %+v", file.Imports)
+	}
+
+	if len(file.Enums) != 1 || file.Enums[0].Comment != "Topic status" {
+		t.Fatalf("enums = %+v", file.Enums)
+	}
+	if got := file.Enums[0].Values[0].TrailingComment; got != "initial state" {
+		t.Errorf("enum value trailing comment = %q", got)
+	}
+
+	topic := file.Messages[0]
+	if topic.Name != "Topic" || topic.Comment != "A thesis topic" {
+		t.Fatalf("message = %q (%q)", topic.Name, topic.Comment)
+	}
+	if len(topic.Fields) != 10 {
+		t.Fatalf("got %d fields, want 10", len(topic.Fields))
+	}
+
+	title := topic.Fields[1]
+	if v, _ := title.Option("json_name"); v != "topicTitle" || title.TrailingComment != "shown in lists" {
+		t.Errorf("title options = %+v, trailing = %q", title.Options, title.TrailingComment)
+	}
+	if topic.Fields[2].Label != "optional" || topic.Fields[3].Label != "repeated" {
+		t.Errorf("labels = %q, %q", topic.Fields[2].Label, topic.Fields[3].Label)
+	}
+	if scores := topic.Fields[4]; !scores.IsMap || scores.KeyType != "string" || scores.ValueType != "int32" {
+		t.Errorf("map field = %+v", scores)
+	}
+	if len(topic.Messages) != 1 || topic.Messages[0].Name != "Note" {
+		t.Errorf("nested messages = %+v", topic.Messages)
+	}
+	if topic.Fields[7].Oneof != "owner" {
+		t.Errorf("oneof member = %+v", topic.Fields[7])
+	}
+	if last := topic.Fields[9]; last.Name != "created_at" || last.Type != "google.protobuf.Timestamp" {
+		t.Errorf("last field = %+v", last)
+	}
+
+	rpcs := file.Services[0].RPCs
+	if len(rpcs) != 2 || rpcs[0].RequestType != "CreateTopicRequest" || rpcs[0].ResponseType != "Topic" {
+		t.Fatalf("rpcs = %+v", rpcs)
+	}
+	if !rpcs[1].ServerStreaming {
+		t.Errorf("expected WatchTopics to be server streaming")
+	}
+}
+
+// writeProtos writes files (keyed by path relative to the project root) into a temp dir
+func writeProtos(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestGetEntityFieldsSkipsNonColumnFields(t *testing.T) {
+	root := writeProtos(t, map[string]string{
+		"proto/thesis/thesis.proto": strings.Replace(sampleProto, `import "proto/common/common.proto";`, "", 1),
+	})
+	registry, err := LoadRegistry(root, "proto/thesis/thesis.proto", "example.com/demo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	file := registry.File("proto/thesis/thesis.proto")
+
+	fields := GetEntityFields(file, registry)["Topic"]
+	var names []string
+	for _, f := range fields {
+		names = append(names, f.Name)
+	}
+	if got := strings.Join(names, ","); got != "title,grade,status" {
+		t.Errorf("entity fields = %s", got)
+	}
+
+	if methods := GetMethods(file, registry); len(methods) != 1 || methods[0].Name != "CreateTopic" {
+		t.Errorf("methods = %+v", methods)
+	}
+
+	required, optional := GetCreateRequestFields(file)
+	if strings.Join(required["Topic"], ",") != "title,status" || strings.Join(optional["Topic"], ",") != "grade" {
+		t.Errorf("required = %v, optional = %v", required["Topic"], optional["Topic"])
+	}
+}
+
+func TestLoadRegistryResolvesImports(t *testing.T) {
+	root := writeProtos(t, map[string]string{
+		"proto/user/user.proto": `syntax = "proto3";
+package user;
+option go_package = "example.com/demo/proto/user";
+enum UserRole {
+  STUDENT = 0;
+  LECTURER = 1;
+}
+message User {
+  enum Level {
+    JUNIOR = 0;
+    SENIOR = 1;
+  }
+}
+`,
+		"proto/thesis/thesis.proto": `syntax = "proto3";
+package thesis;
+option go_package = "./thesis";
+import "google/protobuf/timestamp.proto";
+import "proto/user/user.proto";
+message Topic {
+  string id = 1;
+  user.UserRole owner_role = 2;
+  optional user.User.Level level = 3;
+  google.protobuf.Timestamp deadline = 4;
+}
+`,
+	})
+
+	registry, err := LoadRegistry(root, "proto/thesis/thesis.proto", "example.com/demo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fields := GetEntityFields(registry.File("proto/thesis/thesis.proto"), registry)["Topic"]
+	if len(fields) != 3 {
+		t.Fatalf("got %d fields, want 3", len(fields))
+	}
+
+	role := fields[0]
+	if !role.IsEnum || role.EnumConstPrefix != "userpb.UserRole_" || role.GoPackage != "example.com/demo/proto/user" {
+		t.Errorf("owner_role = %+v", role)
+	}
+	if role.DefaultValue != "STUDENT" || role.DefaultDBValue != "student" {
+		t.Errorf("owner_role defaults = %q/%q", role.DefaultValue, role.DefaultDBValue)
+	}
+	if level := fields[1]; !level.IsEnum || level.EnumConstPrefix != "userpb.User_" {
+		t.Errorf("nested enum level = %+v", level)
+	}
+	if !fields[2].IsTimestamp {
+		t.Errorf("deadline should be a timestamp")
+	}
+
+	imports := registry.Imports([]string{role.GoPackage, "example.com/demo/proto/thesis"})
+	if len(imports) != 1 || imports[0].Alias != "userpb" {
+		t.Errorf("imports = %+v", imports)
+	}
+}
+
+func TestLoadRegistryMissingImport(t *testing.T) {
+	root := writeProtos(t, map[string]string{
+		"proto/thesis/thesis.proto": "syntax = \"proto3\";\npackage thesis;\nimport \"proto/user/user.proto\";\n",
+	})
+
+	_, err := LoadRegistry(root, "proto/thesis/thesis.proto", "example.com/demo")
+	if err == nil || !strings.Contains(err.Error(), `thesis.proto:3:1: import "proto/user/user.proto" not found`) {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestLoadRegistryImportCycle(t *testing.T) {
+	root := writeProtos(t, map[string]string{
+		"proto/thesis/thesis.proto": "syntax = \"proto3\";\npackage thesis;\nimport \"proto/user/user.proto\";\n",
+		"proto/user/user.proto":     "syntax = \"proto3\";\npackage user;\nimport \"proto/thesis/thesis.proto\";\n",
+	})
+
+	_, err := LoadRegistry(root, "proto/thesis/thesis.proto", "example.com/demo")
+	want := "import cycle: proto/thesis/thesis.proto -> proto/user/user.proto -> proto/thesis/thesis.proto"
+	if err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %s", err, want)
+	}
+}
+
+func TestParseProtoErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "missing semicolon",
+			src:  "syntax = \"proto3\";\nmessage A {\n  string name = 1\n}\n",
+			want: "x.proto:4:1: expected ';' but found '}'",
+		},
+		{
+			name: "unterminated message",
+			src:  "message A {\n  string name = 1;\n",
+			want: "x.proto:3:1: expected '}' to close message A but found end of file",
+		},
+		{
+			name: "bad field number",
+			src:  "message A {\n  string name = abc;\n}\n",
+			want: "x.proto:2:17: expected field number but found 'abc'",
+		},
+		{
+			name: "unterminated string",
+			src:  "syntax = \"proto3;\n",
+			want: "x.proto:1:10: unterminated string literal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseProto("x.proto", []byte(tt.src))
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+			if err.Error() != tt.want {
+				t.Errorf("error = %q, want %q", err.Error(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/types"
+)
+
+// TypeInfo describes a message or enum known to the registry
+type TypeInfo struct {
+	FullName  string // fully-qualified proto name without leading dot, e.g. user.UserRole
+	GoPackage string // Go import path of the generated package
+	GoName    string // Go identifier inside GoPackage, e.g. UserRole or Topic_Status
+	// ConstPrefix is the prefix of Go enum constants: top-level enums use
+	// "<Enum>_", enums nested in a message use "<Message>_"
+	ConstPrefix string
+	Enum        *types.ProtoEnum
+	Message     *types.ProtoMessage
+	File        *types.ProtoFile
+}
+
+// IsEnum reports whether the type is an enum
+func (t *TypeInfo) IsEnum() bool {
+	return t.Enum != nil
+}
+
+// wellKnownTypes maps google/protobuf types to their Go packages.
+// Their .proto files ship with protoc, so they are never read from disk.
+var wellKnownTypes = map[string]string{
+	"google.protobuf.Timestamp":   "google.golang.org/protobuf/types/known/timestamppb",
+	"google.protobuf.Duration":    "google.golang.org/protobuf/types/known/durationpb",
+	"google.protobuf.Empty":       "google.golang.org/protobuf/types/known/emptypb",
+	"google.protobuf.Any":         "google.golang.org/protobuf/types/known/anypb",
+	"google.protobuf.Struct":      "google.golang.org/protobuf/types/known/structpb",
+	"google.protobuf.Value":       "google.golang.org/protobuf/types/known/structpb",
+	"google.protobuf.ListValue":   "google.golang.org/protobuf/types/known/structpb",
+	"google.protobuf.FieldMask":   "google.golang.org/protobuf/types/known/fieldmaskpb",
+	"google.protobuf.StringValue": "google.golang.org/protobuf/types/known/wrapperspb",
+	"google.protobuf.BoolValue":   "google.golang.org/protobuf/types/known/wrapperspb",
+	"google.protobuf.Int32Value":  "google.golang.org/protobuf/types/known/wrapperspb",
+	"google.protobuf.Int64Value":  "google.golang.org/protobuf/types/known/wrapperspb",
+	"google.protobuf.UInt32Value": "google.golang.org/protobuf/types/known/wrapperspb",
+	"google.protobuf.UInt64Value": "google.golang.org/protobuf/types/known/wrapperspb",
+	"google.protobuf.FloatValue":  "google.golang.org/protobuf/types/known/wrapperspb",
+	"google.protobuf.DoubleValue": "google.golang.org/protobuf/types/known/wrapperspb",
+	"google.protobuf.BytesValue":  "google.golang.org/protobuf/types/known/wrapperspb",
+}
+
+// Registry holds every type reachable from a proto file through its imports
+type Registry struct {
+	root       string
+	modulePath string
+	files      map[string]*types.ProtoFile // keyed by import path
+	typesByFQN map[string]*TypeInfo
+	aliases    map[string]string // Go import path -> alias
+	// primary is the Go package of the file being generated; its alias is "pb"
+	primary string
+}
+
+// LoadRegistry parses filename (a path relative to root, e.g. proto/user/user.proto)
+// and every file it imports, resolving imports relative to the project root
+func LoadRegistry(root, filename, modulePath string) (*Registry, error) {
+	r := &Registry{
+		root:       root,
+		modulePath: modulePath,
+		files:      make(map[string]*types.ProtoFile),
+		typesByFQN: make(map[string]*TypeInfo),
+		aliases:    make(map[string]string),
+	}
+
+	for name, goPackage := range wellKnownTypes {
+		r.typesByFQN[name] = &TypeInfo{
+			FullName:  name,
+			GoPackage: goPackage,
+			GoName:    name[strings.LastIndex(name, ".")+1:],
+		}
+	}
+
+	importPath := filepath.ToSlash(filename)
+	if _, err := r.load(importPath, nil); err != nil {
+		return nil, err
+	}
+
+	r.primary = r.GoPackage(r.files[importPath])
+	r.aliases[r.primary] = "pb"
+	return r, nil
+}
+
+// load parses one file (once) and recursively loads its imports. loading is
+// the chain of files whose imports are being loaded; a file is stored before
+// its imports, so the chain is checked first to catch cycles.
+func (r *Registry) load(importPath string, loading []string) (*types.ProtoFile, error) {
+	for _, p := range loading {
+		if p == importPath {
+			return nil, fmt.Errorf("import cycle: %s -> %s", strings.Join(loading, " -> "), importPath)
+		}
+	}
+	if file, ok := r.files[importPath]; ok {
+		return file, nil
+	}
+
+	file, err := ParseProtoFile(filepath.Join(r.root, filepath.FromSlash(importPath)))
+	if err != nil {
+		return nil, err
+	}
+	r.files[importPath] = file
+
+	for _, imp := range file.Imports {
+		if strings.HasPrefix(imp.Path, "google/protobuf/") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(r.root, filepath.FromSlash(imp.Path))); err != nil {
+			return nil, &Error{
+				Filename: file.Filename,
+				Line:     imp.Pos.Line,
+				Column:   imp.Pos.Column,
+				Msg:      fmt.Sprintf("import %q not found relative to project root", imp.Path),
+			}
+		}
+		if _, err := r.load(imp.Path, append(loading, importPath)); err != nil {
+			return nil, err
+		}
+	}
+
+	r.register(file)
+	return file, nil
+}
+
+// register adds every message and enum declared in file to the registry
+func (r *Registry) register(file *types.ProtoFile) {
+	goPackage := r.GoPackage(file)
+	prefix := ""
+	if file.Package != "" {
+		prefix = file.Package + "."
+	}
+
+	var addEnum func(enum *types.ProtoEnum, scope, goScope string)
+	addEnum = func(enum *types.ProtoEnum, scope, goScope string) {
+		info := &TypeInfo{
+			FullName:    scope + enum.Name,
+			GoPackage:   goPackage,
+			GoName:      goScope + enum.Name,
+			ConstPrefix: enum.Name + "_",
+			Enum:        enum,
+			File:        file,
+		}
+		if goScope != "" {
+			// Nested enum constants are prefixed with the parent message name
+			info.ConstPrefix = goScope
+		}
+		r.typesByFQN[info.FullName] = info
+	}
+
+	var addMessage func(msg *types.ProtoMessage, scope, goScope string)
+	addMessage = func(msg *types.ProtoMessage, scope, goScope string) {
+		r.typesByFQN[scope+msg.Name] = &TypeInfo{
+			FullName:  scope + msg.Name,
+			GoPackage: goPackage,
+			GoName:    goScope + msg.Name,
+			Message:   msg,
+			File:      file,
+		}
+		for _, enum := range msg.Enums {
+			addEnum(enum, scope+msg.Name+".", goScope+msg.Name+"_")
+		}
+		for _, nested := range msg.Messages {
+			addMessage(nested, scope+msg.Name+".", goScope+msg.Name+"_")
+		}
+	}
+
+	for _, enum := range file.Enums {
+		addEnum(enum, prefix, "")
+	}
+	for _, msg := range file.Messages {
+		addMessage(msg, prefix, "")
+	}
+}
+
+// GoPackage returns the Go import path of a parsed file. A go_package that is a
+// full import path is used as-is; relative values such as "./thesis" fall back
+// to <module>/<directory of the proto file>.
+func (r *Registry) GoPackage(file *types.ProtoFile) string {
+	if goPackage, ok := file.Option("go_package"); ok {
+		if i := strings.Index(goPackage, ";"); i >= 0 {
+			goPackage = goPackage[:i]
+		}
+		if strings.Contains(goPackage, "/") && !strings.HasPrefix(goPackage, ".") {
+			return goPackage
+		}
+	}
+
+	rel, err := filepath.Rel(r.root, filepath.Dir(file.Filename))
+	if err != nil {
+		rel = filepath.Dir(file.Filename)
+	}
+	return path.Join(r.modulePath, filepath.ToSlash(rel))
+}
+
+// File returns a parsed file by import path (e.g. proto/user/user.proto)
+func (r *Registry) File(importPath string) *types.ProtoFile {
+	return r.files[filepath.ToSlash(importPath)]
+}
+
+// Resolve looks up a type reference the way protoc does: starting from the
+// innermost scope (e.g. "thesis.Topic") and walking outwards to the root
+func (r *Registry) Resolve(scope, name string) *TypeInfo {
+	if strings.HasPrefix(name, ".") {
+		return r.typesByFQN[name[1:]]
+	}
+
+	for {
+		candidate := name
+		if scope != "" {
+			candidate = scope + "." + name
+		}
+		if info, ok := r.typesByFQN[candidate]; ok {
+			return info
+		}
+		if scope == "" {
+			return nil
+		}
+		if i := strings.LastIndex(scope, "."); i >= 0 {
+			scope = scope[:i]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+// Alias returns the import alias used for a Go package in generated code.
+// The package being generated is always "pb"; well-known types keep their
+// package name and other proto packages get a "pb" suffix (user -> userpb).
+func (r *Registry) Alias(goPackage string) string {
+	if alias, ok := r.aliases[goPackage]; ok {
+		return alias
+	}
+
+	alias := path.Base(goPackage)
+	alias = strings.Map(func(c rune) rune {
+		if isLetter(c) || isDigit(c) {
+			return c
+		}
+		return '_'
+	}, alias)
+	if !strings.HasPrefix(goPackage, "google.golang.org/protobuf/") && !strings.HasSuffix(alias, "pb") {
+		alias += "pb"
+	}
+
+	base := alias
+	for i := 2; r.aliasTaken(alias); i++ {
+		alias = fmt.Sprintf("%s%d", base, i)
+	}
+	r.aliases[goPackage] = alias
+	return alias
+}
+
+func (r *Registry) aliasTaken(alias string) bool {
+	for _, existing := range r.aliases {
+		if existing == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// GoType returns the package-qualified Go type expression for a type, e.g.
+// pb.Topic or userpb.UserRole
+func (r *Registry) GoType(info *TypeInfo) string {
+	return r.Alias(info.GoPackage) + "." + info.GoName
+}
+
+// Imports returns the extra Go imports needed for the given qualified types,
+// excluding the primary package and any path in skip
+func (r *Registry) Imports(goPackages []string, skip ...string) []types.GoImport {
+	seen := make(map[string]bool)
+	for _, p := range skip {
+		seen[p] = true
+	}
+	seen[r.primary] = true
+
+	var imports []types.GoImport
+	for _, p := range goPackages {
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		imports = append(imports, types.GoImport{Alias: r.Alias(p), Path: p})
+	}
+	return imports
+}
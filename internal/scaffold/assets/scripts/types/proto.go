@@ -0,0 +1,139 @@
+package types
+
+// Position is a location inside a proto source file (1-based)
+type Position struct {
+	Line   int
+	Column int
+}
+
+// ProtoFile is the in-memory descriptor model of a single .proto file
+type ProtoFile struct {
+	Filename string
+	Syntax   string
+	Package  string
+	Imports  []ProtoImport
+	Options  []ProtoOption
+	Messages []*ProtoMessage
+	Enums    []*ProtoEnum
+	Services []*ProtoService
+	Extends  []*ProtoExtend
+}
+
+// ProtoImport is an `import` statement
+type ProtoImport struct {
+	Path     string
+	Modifier string // "", "public" or "weak"
+	Pos      Position
+}
+
+// ProtoOption is an `option` statement or a [name = value] field option.
+// Name keeps parentheses for custom options, e.g. "(grpcgen.table)".
+// Value is the literal without quotes; aggregate values keep their raw text.
+type ProtoOption struct {
+	Name  string
+	Value string
+	Pos   Position
+}
+
+// ProtoMessage is a `message` declaration with its nested declarations
+type ProtoMessage struct {
+	Name     string
+	Fields   []*ProtoField
+	Messages []*ProtoMessage
+	Enums    []*ProtoEnum
+	Oneofs   []string
+	Options  []ProtoOption
+	Comment  string
+	Pos      Position
+	EndPos   Position // position of the closing brace
+}
+
+// ProtoField is a field of a message (including map fields and oneof members)
+type ProtoField struct {
+	Name            string
+	Type            string // for map fields: "map<KeyType, ValueType>"
+	Number          int
+	Label           string // "", "optional", "required" or "repeated"
+	IsMap           bool
+	KeyType         string
+	ValueType       string
+	Oneof           string // name of the enclosing oneof, if any
+	Options         []ProtoOption
+	Comment         string
+	TrailingComment string
+	Pos             Position
+}
+
+// ProtoEnum is an `enum` declaration
+type ProtoEnum struct {
+	Name    string
+	Values  []*ProtoEnumValue
+	Options []ProtoOption
+	Comment string
+	Pos     Position
+	EndPos  Position
+}
+
+// ProtoEnumValue is a single enum constant
+type ProtoEnumValue struct {
+	Name            string
+	Number          int
+	Options         []ProtoOption
+	Comment         string
+	TrailingComment string
+	Pos             Position
+}
+
+// ProtoService is a `service` declaration
+type ProtoService struct {
+	Name    string
+	RPCs    []*ProtoRPC
+	Options []ProtoOption
+	Comment string
+	Pos     Position
+	EndPos  Position
+}
+
+// ProtoRPC is an `rpc` declaration inside a service
+type ProtoRPC struct {
+	Name            string
+	RequestType     string
+	ResponseType    string
+	ClientStreaming bool
+	ServerStreaming bool
+	Options         []ProtoOption
+	Comment         string
+	TrailingComment string
+	Pos             Position
+}
+
+// ProtoExtend is an `extend` block (used to declare custom options)
+type ProtoExtend struct {
+	Extendee string
+	Fields   []*ProtoField
+	Pos      Position
+}
+
+// Option returns the value of the named option and whether it was set
+func (m *ProtoMessage) Option(name string) (string, bool) {
+	return findOption(m.Options, name)
+}
+
+// Option returns the value of the named option and whether it was set
+func (f *ProtoField) Option(name string) (string, bool) {
+	return findOption(f.Options, name)
+}
+
+// Option returns the value of the named file-level option and whether it was set
+func (f *ProtoFile) Option(name string) (string, bool) {
+	return findOption(f.Options, name)
+}
+
+func findOption(options []ProtoOption, name string) (string, bool) {
+	for _, opt := range options {
+		if opt.Name == name {
+			return opt.Value, true
+		}
+	}
+	return "", false
+}
@@ -1,11 +1,17 @@
 package types
 
+import "strings"
+
 type Data struct {
 	PackagePath string
 	ProtoName   string
 	ServiceName string
 	Port        string
 	ModulePath  string
+	Dialect     string // database selected with grpc-gen init --db
+	TLSMode     string // mtls, tls or insecure (grpc-gen.yaml tls)
+	Migrate     string // DB_MIGRATE (grpc-gen.yaml migrate)
+	Trace       string // TRACE_MODE (grpc-gen.yaml trace)
 }
 
 type HandlerData struct {
@@ -18,49 +24,143 @@ type Method struct {
 	Name         string
 	RequestType  string
 	ResponseType string
+	// Package-qualified Go types, e.g. pb.CreateUserRequest or emptypb.Empty
+	RequestGoType  string
+	ResponseGoType string
+	// Go import paths referenced by the request and response types
+	GoPackages []string
+	// ListEntity and ListField name the repeated message field of a List
+	// response: its message type and Go field name, e.g. Course and Courses
+	ListEntity string
+	ListField  string
+}
+
+// GoImport is an extra Go package imported by a generated file
+type GoImport struct {
+	Alias string
+	Path  string
 }
 
 type EntityHandlerData struct {
 	PackagePath string
 	EntityName  string
 	Methods     []Method
+	Imports     []GoImport
 }
 
 type Field struct {
-	Name           string
-	ProtoName      string
-	Type           string
-	GoName         string
-	DBField        string
-	EnumType       string
-	EnumValues     []string
+	Name       string
+	ProtoName  string
+	Type       string
+	GoName     string
+	DBField    string
+	EnumType   string
+	EnumValues []string
+	// EnumValuePrefix is the enum name in UPPER_SNAKE_CASE plus "_", e.g.
+	// ENROLLMENT_STATUS_; values carrying it are stored without it
+	EnumValuePrefix string
+	// EnumConstPrefix prefixes Go enum constants, e.g. pb.TopicStatus_ or userpb.UserRole_
+	EnumConstPrefix string
+	// GoPackage is the Go import path of the package declaring the enum type
+	GoPackage      string
 	DefaultValue   string
 	DefaultDBValue string
 	IsOptional     bool
 	IsEnum         bool
 	IsTimestamp    bool
+	// Whether List may filter / sort by this field (grpcgen.filterable / grpcgen.sortable)
+	IsFilterable bool
+	IsSortable   bool
+	// Whether migrations index the column (grpcgen.index)
+	IsIndexed bool
+}
+
+// EnumDBValue returns the database value of an enum value: its name in
+// lower case without EnumValuePrefix, e.g. ENROLLMENT_STATUS_ACTIVE -> active
+func (f Field) EnumDBValue(value string) string {
+	if short := strings.TrimPrefix(value, f.EnumValuePrefix); short != "" {
+		value = short
+	}
+	return strings.ToLower(value)
+}
+
+// FilterKind returns the helper.ValueKind constant filter values of the field
+// are parsed as
+func (f Field) FilterKind() string {
+	switch {
+	case f.IsEnum:
+		return "helper.EnumValue"
+	case f.IsTimestamp:
+		return "helper.TimestampValue"
+	}
+	switch f.Type {
+	case "int32", "int64", "sint32", "sint64", "sfixed32", "sfixed64":
+		return "helper.IntValue"
+	case "uint32", "uint64", "fixed32", "fixed64":
+		return "helper.UintValue"
+	case "float", "double":
+		return "helper.FloatValue"
+	case "bool":
+		return "helper.BoolValue"
+	}
+	return "helper.StringValue"
 }
 
 type CRUDHandlerData struct {
-	ModulePath           string
-	PackagePath          string
-	EntityName           string
-	TableName            string
-	Methods              []Method
-	EnumType             string
-	RequiredFields       []Field
-	OptionalFields       []Field
-	EnumFields           []Field
-	FilterableFields     []string
-	CreateFields         []Field
-	CreateFieldsSQL      string
-	CreatePlaceholders   string
-	UpdateFields         []Field
-	SelectFieldsSQL      string
-	ScanFields              []string
-	OptionalEntityFields    []string // Optional field names in entity (created_by, updated_by, etc)
+	ModulePath               string
+	PackagePath              string
+	Imports                  []GoImport
+	EntityName               string
+	TableName                string
+	Methods                  []Method
+	EnumType                 string
+	RequiredFields           []Field
+	OptionalFields           []Field
+	EnumFields               []Field
+	FilterableFields         []Field
+	SortableFields           []Field
+	CreateFields             []Field
+	CreateFieldsSQL          string
+	CreatePlaceholders       string
+	UpdateFields             []Field
+	SelectFieldsSQL          string
+	ScanFields               []string
+	OptionalEntityFields     []string // Optional field names in entity (created_by, updated_by, etc)
 	OptionalEntityFieldsData []Field  // Optional entity fields with full metadata for scanning
-	OptionalUpdateFields    []string // Optional fields in UpdateRequest
-	IsCreatedByOptional     bool     // Whether created_by is optional in CreateRequest
-	IsUpdatedByOptional     bool     // Whether updated_by is optional in UpdateRequest
+	OptionalUpdateFields     []string // Optional fields in UpdateRequest
+	IsCreatedByOptional      bool     // Whether created_by is optional in CreateRequest
+	IsUpdatedByOptional      bool     // Whether updated_by is optional in UpdateRequest
+	HasNextPageToken         bool     // Whether the List response has next_page_token
+	ListField                string   // List response field holding the entities, e.g. Topics
+}
+
+// TestField is an entity field with the two values the generated handler
+// tests write. Low sorts before High in the database.
+type TestField struct {
+	Field
+	Low  string // Go expression
+	High string // Go expression
+	// Low and High as filter values, the way they are stored
+	LowFilter  string
+	HighFilter string
+	// Whether the field is optional in the Update request
+	IsUpdateOptional bool
+}
+
+type CRUDTestData struct {
+	CRUDHandlerData
+	CreateMethod Method
+	UpdateMethod Method
+	DeleteMethod Method
+	ListMethod   Method
+	// Imports referenced by enum constants of the tested fields
+	TestImports []GoImport
+	// SQLite CREATE TABLE of the entity, rendered by the migration dialect
+	Schema       string
+	Fields       []TestField
+	FilterFields []TestField
+	SortFields   []TestField
+	// Whether a filter field is a string, which the text operators apply to
+	HasTextFilter     bool
+	HasOptionalUpdate bool
 }
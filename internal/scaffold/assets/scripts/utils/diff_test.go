@@ -0,0 +1,41 @@
+package utils
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	new := "a\nb\nC\nd\ne\nf\ng\nh\ni\nj\nk\n"
+
+	want := `--- a/x.go
++++ b/x.go
+@@ -1,6 +1,6 @@
+ a
+ b
+-c
++C
+ d
+ e
+ f
+@@ -8,3 +8,4 @@
+ h
+ i
+ j
++k
+`
+	if got := UnifiedDiff("x.go", old, new); got != want {
+		t.Errorf("diff mismatch:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiffNewFile(t *testing.T) {
+	want := "--- /dev/null\n+++ b/x.go\n@@ -0,0 +1,2 @@\n+a\n+b\n"
+	if got := UnifiedDiff("x.go", "", "a\nb\n"); got != want {
+		t.Errorf("diff mismatch:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiffEqual(t *testing.T) {
+	if got := UnifiedDiff("x.go", "a\n", "a\n"); got != "" {
+		t.Errorf("expected no diff, got:\n%s", got)
+	}
+}
@@ -0,0 +1,119 @@
+package utils
+
+import "strings"
+
+// change replaces the base lines [start, end) with lines
+type change struct {
+	start, end int
+	lines      []string
+}
+
+// changes lists the edits that turn base into other
+func changes(base, other []string) []change {
+	var result []change
+	var cur *change
+	i := 0
+	for _, op := range diffLines(base, other) {
+		if op.kind == ' ' {
+			if cur != nil {
+				result = append(result, *cur)
+				cur = nil
+			}
+			i++
+			continue
+		}
+		if cur == nil {
+			cur = &change{start: i, end: i}
+		}
+		if op.kind == '-' {
+			i++
+			cur.end = i
+		} else {
+			cur.lines = append(cur.lines, op.text)
+		}
+	}
+	if cur != nil {
+		result = append(result, *cur)
+	}
+	return result
+}
+
+// apply returns base[start:end] with changes (all inside the range) applied
+func apply(base []string, start, end int, changes []change) []string {
+	var out []string
+	pos := start
+	for _, c := range changes {
+		out = append(out, base[pos:c.start]...)
+		out = append(out, c.lines...)
+		pos = c.end
+	}
+	return append(out, base[pos:end]...)
+}
+
+// Merge3 merges the edits from base to ours and from base to theirs, line by
+// line. Edits that overlap or touch are kept as a conflict between
+// "<<<<<<< oursLabel", "=======" and ">>>>>>> theirsLabel" markers unless both
+// sides made the same edit. It returns the merged text and the number of
+// conflicts.
+func Merge3(base, ours, theirs, oursLabel, theirsLabel string) (string, int) {
+	if ours == theirs || theirs == base {
+		return ours, 0
+	}
+	if ours == base {
+		return theirs, 0
+	}
+
+	b := splitLines(base)
+	oc, tc := changes(b, splitLines(ours)), changes(b, splitLines(theirs))
+
+	var out []string
+	conflicts, pos := 0, 0
+	for len(oc) > 0 || len(tc) > 0 {
+		// Start a group with the first change, then take in every change of
+		// either side that overlaps or touches it
+		var ours, theirs []change
+		var start, end int
+		if len(tc) == 0 || (len(oc) > 0 && oc[0].start <= tc[0].start) {
+			ours, oc = append(ours, oc[0]), oc[1:]
+			start, end = ours[0].start, ours[0].end
+		} else {
+			theirs, tc = append(theirs, tc[0]), tc[1:]
+			start, end = theirs[0].start, theirs[0].end
+		}
+		for grouping := true; grouping; {
+			switch {
+			case len(oc) > 0 && oc[0].start <= end:
+				end = max(end, oc[0].end)
+				ours, oc = append(ours, oc[0]), oc[1:]
+			case len(tc) > 0 && tc[0].start <= end:
+				end = max(end, tc[0].end)
+				theirs, tc = append(theirs, tc[0]), tc[1:]
+			default:
+				grouping = false
+			}
+		}
+
+		out = append(out, b[pos:start]...)
+		o, t := apply(b, start, end, ours), apply(b, start, end, theirs)
+		switch {
+		case len(theirs) == 0:
+			out = append(out, o...)
+		case len(ours) == 0 || strings.Join(o, "\n") == strings.Join(t, "\n"):
+			out = append(out, t...)
+		default:
+			conflicts++
+			out = append(out, "<<<<<<< "+oursLabel)
+			out = append(out, o...)
+			out = append(out, "=======")
+			out = append(out, t...)
+			out = append(out, ">>>>>>> "+theirsLabel)
+		}
+		pos = end
+	}
+	out = append(out, b[pos:]...)
+
+	if len(out) == 0 {
+		return "", conflicts
+	}
+	return strings.Join(out, "\n") + "\n", conflicts
+}
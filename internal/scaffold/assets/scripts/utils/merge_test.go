@@ -0,0 +1,71 @@
+package utils
+
+import "testing"
+
+func TestMerge3(t *testing.T) {
+	base := "a\nb\nc\nd\ne\nf\ng\n"
+
+	tests := []struct {
+		name          string
+		ours, theirs  string
+		want          string
+		wantConflicts int
+	}{
+		{
+			name:   "only theirs changed",
+			ours:   base,
+			theirs: "a\nB\nc\nd\ne\nf\ng\n",
+			want:   "a\nB\nc\nd\ne\nf\ng\n",
+		},
+		{
+			name:   "only ours changed",
+			ours:   "a\nb\nc\nD\ne\nf\ng\n",
+			theirs: base,
+			want:   "a\nb\nc\nD\ne\nf\ng\n",
+		},
+		{
+			name:   "separate edits",
+			ours:   "a\nB\nc\nd\ne\nf\ng\n",
+			theirs: "a\nb\nc\nd\ne\nF\ng\nh\n",
+			want:   "a\nB\nc\nd\ne\nF\ng\nh\n",
+		},
+		{
+			name:   "same edit on both sides",
+			ours:   "a\nB\nc\nd\ne\nf\ng\n",
+			theirs: "a\nB\nc\nd\ne\nF\ng\n",
+			want:   "a\nB\nc\nd\ne\nF\ng\n",
+		},
+		{
+			name:   "deletion and insertion elsewhere",
+			ours:   "a\nc\nd\ne\nf\ng\n",
+			theirs: "a\nb\nc\nd\ne\nf\ng\nh\n",
+			want:   "a\nc\nd\ne\nf\ng\nh\n",
+		},
+		{
+			name:          "overlapping edits",
+			ours:          "a\nb\nX\nd\ne\nf\ng\n",
+			theirs:        "a\nb\nY\nd\ne\nf\ng\n",
+			want:          "a\nb\n<<<<<<< yours\nX\n=======\nY\n>>>>>>> new\nd\ne\nf\ng\n",
+			wantConflicts: 1,
+		},
+		{
+			name:          "touching edits conflict",
+			ours:          "a\nB\nc\nd\ne\nf\ng\n",
+			theirs:        "a\nb\nC\nd\ne\nf\ng\n",
+			want:          "a\n<<<<<<< yours\nB\nc\n=======\nb\nC\n>>>>>>> new\nd\ne\nf\ng\n",
+			wantConflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Merge3(base, tt.ours, tt.theirs, "yours", "new")
+			if got != tt.want {
+				t.Errorf("merge mismatch:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if conflicts != tt.wantConflicts {
+				t.Errorf("conflicts = %d, want %d", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
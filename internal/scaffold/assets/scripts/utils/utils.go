@@ -36,6 +36,22 @@ func ToCamelCase(s string) string {
 	return result.String()
 }
 
+// Pluralize returns the plural of an entity name
+// Examples: Faculty -> Faculties, Course -> Courses, Class -> Classes
+func Pluralize(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case name == "":
+		return name
+	case strings.HasSuffix(lower, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
+
 // NormalizePlural converts plural entity names to singular
 // Examples: Faculties -> Faculty, Semesters -> Semester
 func NormalizePlural(name string) string {
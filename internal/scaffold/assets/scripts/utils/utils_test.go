@@ -0,0 +1,20 @@
+package utils
+
+import "testing"
+
+func TestPluralize(t *testing.T) {
+	tests := map[string]string{
+		"Topic":   "Topics",
+		"Faculty": "Faculties",
+		"Survey":  "Surveys",
+		"Course":  "Courses",
+		"Class":   "Classes",
+		"Box":     "Boxes",
+		"Batch":   "Batches",
+	}
+	for name, want := range tests {
+		if got := Pluralize(name); got != want {
+			t.Errorf("Pluralize(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -3,16 +3,21 @@ package config
 import (
 	"bufio"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"strings"
 )
 
 type Config struct {
+	DBDriver   string // mysql, postgres or sqlite
 	DBHost     string
 	DBPort     string
 	DBUser     string
 	DBPassword string
 	DBName     string
+	DBSSLMode  string // postgres only (default: disable)
+	DBPath     string // sqlite only: database file or :memory: (default)
 }
 
 func Load(envPath string) (*Config, error) {
@@ -40,6 +45,12 @@ func Load(envPath string) (*Config, error) {
 		value := strings.TrimSpace(parts[1])
 
 		switch key {
+		case "DB_DRIVER":
+			cfg.DBDriver = value
+		case "DB_SSLMODE":
+			cfg.DBSSLMode = value
+		case "DB_PATH":
+			cfg.DBPath = value
 		case "DB_HOST":
 			cfg.DBHost = value
 		case "DB_PORT":
@@ -58,6 +69,15 @@ func Load(envPath string) (*Config, error) {
 	}
 
 	// Override with environment variables if set
+	if driver := os.Getenv("DB_DRIVER"); driver != "" {
+		cfg.DBDriver = driver
+	}
+	if sslMode := os.Getenv("DB_SSLMODE"); sslMode != "" {
+		cfg.DBSSLMode = sslMode
+	}
+	if path := os.Getenv("DB_PATH"); path != "" {
+		cfg.DBPath = path
+	}
 	if host := os.Getenv("DB_HOST"); host != "" {
 		cfg.DBHost = host
 	}
@@ -77,7 +97,41 @@ func Load(envPath string) (*Config, error) {
 	return cfg, nil
 }
 
+// Validate checks that the settings required by the driver are present
+func (c *Config) Validate() error {
+	if c.DBDriver == "sqlite" {
+		return nil
+	}
+	if c.DBHost == "" || c.DBPort == "" || c.DBUser == "" || c.DBName == "" {
+		return fmt.Errorf("missing required database environment variables")
+	}
+	return nil
+}
+
+// GetDSN returns the connection string for the configured driver
 func (c *Config) GetDSN() string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
-		c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName)
+	switch c.DBDriver {
+	case "sqlite":
+		path := c.DBPath
+		if path == "" {
+			path = ":memory:"
+		}
+		return path + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+	case "postgres":
+		sslMode := c.DBSSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(c.DBUser, c.DBPassword),
+			Host:     net.JoinHostPort(c.DBHost, c.DBPort),
+			Path:     c.DBName,
+			RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+		}
+		return dsn.String()
+	default:
+		return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&loc=Local",
+			c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName)
+	}
 }
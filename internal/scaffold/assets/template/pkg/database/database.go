@@ -9,7 +9,7 @@ import (
 	"sync"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"thaily/src/service/pkg/config"
 )
 
 var (
@@ -72,11 +72,19 @@ func Connect(dsn string) (*sql.DB, error) {
 }
 
 func ConnectWithConfig(dsn string, config ConnectionPoolConfig) (*sql.DB, error) {
-	db, err := sql.Open("mysql", dsn)
+	dialect, err := selectDialect()
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(dialect.DriverName, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	if dialect.SingleConnection {
+		config = ConnectionPoolConfig{MaxOpenConns: 1, MaxIdleConns: 1}
+	}
+
 	// Cấu hình Connection Pool để tối ưu và tránh "too many connections"
 
 	// Số lượng connection tối đa có thể mở (bao gồm cả đang dùng và idle)
@@ -110,22 +118,33 @@ func ConnectWithConfig(dsn string, config ConnectionPoolConfig) (*sql.DB, error)
 	return db, nil
 }
 
-// InitDB initializes the global database connection from environment variables
+// InitDB initializes the global database connection from environment variables.
+// DB_DRIVER selects the dialect (default: DefaultDriver).
 func InitDB() error {
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
-
-	if dbHost == "" || dbPort == "" || dbUser == "" || dbName == "" {
-		return fmt.Errorf("missing required database environment variables")
+	driver := os.Getenv("DB_DRIVER")
+	if driver == "" {
+		driver = DefaultDriver
+	}
+	if err := SetDialect(driver); err != nil {
+		return err
+	}
+
+	cfg := &config.Config{
+		DBDriver:   driver,
+		DBHost:     os.Getenv("DB_HOST"),
+		DBPort:     os.Getenv("DB_PORT"),
+		DBUser:     os.Getenv("DB_USER"),
+		DBPassword: os.Getenv("DB_PASSWORD"),
+		DBName:     os.Getenv("DB_NAME"),
+		DBSSLMode:  os.Getenv("DB_SSLMODE"),
+		DBPath:     os.Getenv("DB_PATH"),
 	}
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&loc=Local",
-		dbUser, dbPassword, dbHost, dbPort, dbName)
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
 
-	db, err := Connect(dsn)
+	db, err := Connect(cfg.GetDSN())
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Dialect describes the differences between the supported databases.
+// Each dialect_<name>.go file registers one in init().
+type Dialect struct {
+	// Name is the value of DB_DRIVER, e.g. "mysql", "postgres" or "sqlite"
+	Name string
+	// DriverName is the database/sql driver name passed to sql.Open
+	DriverName string
+	// NumberedPlaceholders is true when the driver expects $1, $2... instead of ?
+	NumberedPlaceholders bool
+	// IdentifierQuote quotes table and column names; 0 keeps the standard
+	// double quote
+	IdentifierQuote byte
+	// TimestampType is the column type used for timestamps in schema_migrations
+	TimestampType string
+	// IsUniqueViolation reports whether err is a unique/primary key violation
+	IsUniqueViolation func(err error) bool
+	// Lock takes an advisory lock on conn and returns the function releasing it
+	Lock func(ctx context.Context, conn *sql.Conn, name string) (func(), error)
+	// SingleConnection keeps the pool at one long-lived connection (SQLite:
+	// one writer, and every connection to :memory: is a separate database)
+	SingleConnection bool
+}
+
+var (
+	dialects       = make(map[string]*Dialect)
+	currentDialect *Dialect
+	dialectMutex   sync.RWMutex
+)
+
+// RegisterDialect makes a dialect available to SetDialect
+func RegisterDialect(d *Dialect) {
+	dialectMutex.Lock()
+	defer dialectMutex.Unlock()
+	dialects[d.Name] = d
+}
+
+// SetDialect selects the dialect used by Connect, Rebind and the migrator
+func SetDialect(name string) error {
+	dialectMutex.Lock()
+	defer dialectMutex.Unlock()
+
+	d, ok := dialects[name]
+	if !ok {
+		var names []string
+		for n := range dialects {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unsupported DB_DRIVER %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	currentDialect = d
+	return nil
+}
+
+// CurrentDialect returns the selected dialect: the one set with SetDialect,
+// otherwise the one of DB_DRIVER, otherwise DefaultDriver's. An unsupported
+// DB_DRIVER is reported by Connect, not here.
+func CurrentDialect() *Dialect {
+	dialectMutex.RLock()
+	defer dialectMutex.RUnlock()
+
+	if currentDialect != nil {
+		return currentDialect
+	}
+	if d, ok := dialects[os.Getenv("DB_DRIVER")]; ok {
+		return d
+	}
+	return dialects[DefaultDriver]
+}
+
+// selectDialect returns the dialect set with SetDialect or selects the one of
+// DB_DRIVER (default: DefaultDriver), failing when it is not supported
+func selectDialect() (*Dialect, error) {
+	dialectMutex.RLock()
+	d := currentDialect
+	dialectMutex.RUnlock()
+	if d != nil {
+		return d, nil
+	}
+
+	name := os.Getenv("DB_DRIVER")
+	if name == "" {
+		name = DefaultDriver
+	}
+	if err := SetDialect(name); err != nil {
+		return nil, err
+	}
+	return CurrentDialect(), nil
+}
+
+// IsUniqueViolation reports whether err is a unique constraint violation in
+// the current dialect (used to map inserts to codes.AlreadyExists)
+func IsUniqueViolation(err error) bool {
+	return err != nil && CurrentDialect().IsUniqueViolation(err)
+}
+
+// Rebind adapts a query written with ? placeholders and "double-quoted"
+// identifiers, as generated handlers and the helper package write them, to
+// the current dialect: ? becomes $N for dialects with numbered placeholders
+// and "name" becomes `name` on MySQL. Existing $N placeholders are kept and
+// numbering continues after them, so the query must list arguments in the
+// order they appear in the text. Double quotes therefore cannot delimit
+// string literals.
+func Rebind(query string) string {
+	d := CurrentDialect()
+	identQuote := d.IdentifierQuote
+	if identQuote == 0 {
+		identQuote = '"'
+	}
+	numbered := d.NumberedPlaceholders && strings.Contains(query, "?")
+	if !numbered && (identQuote == '"' || !strings.Contains(query, `"`)) {
+		return query
+	}
+
+	var sb strings.Builder
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+				if c == '"' {
+					c = identQuote
+				}
+			}
+		case c == '\'' || c == '`':
+			quote = c
+		case c == '"':
+			quote = c
+			c = identQuote
+		case !numbered:
+		case c == '$':
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			if j > i+1 {
+				if v, err := strconv.Atoi(query[i+1 : j]); err == nil && v > n {
+					n = v
+				}
+				sb.WriteString(query[i:j])
+				i = j - 1
+				continue
+			}
+		case c == '?':
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
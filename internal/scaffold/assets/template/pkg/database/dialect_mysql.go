@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func init() {
+	RegisterDialect(&Dialect{
+		Name:            "mysql",
+		DriverName:      "mysql",
+		IdentifierQuote: '`',
+		TimestampType:   "DATETIME",
+		IsUniqueViolation: func(err error) bool {
+			var mysqlErr *mysql.MySQLError
+			// 1062: ER_DUP_ENTRY
+			return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+		},
+		Lock: func(ctx context.Context, conn *sql.Conn, name string) (func(), error) {
+			var acquired sql.NullInt64
+			if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, int(lockTimeout.Seconds())).Scan(&acquired); err != nil {
+				return nil, err
+			}
+			if acquired.Int64 != 1 {
+				return nil, fmt.Errorf("timed out waiting for lock %s", name)
+			}
+			return func() {
+				conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name)
+			}, nil
+		},
+	})
+}
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func init() {
+	RegisterDialect(&Dialect{
+		Name:                 "postgres",
+		DriverName:           "pgx",
+		NumberedPlaceholders: true,
+		TimestampType:        "TIMESTAMP",
+		IsUniqueViolation: func(err error) bool {
+			var pgErr *pgconn.PgError
+			// 23505: unique_violation
+			return errors.As(err, &pgErr) && pgErr.Code == "23505"
+		},
+		Lock: func(ctx context.Context, conn *sql.Conn, name string) (func(), error) {
+			// Session-level advisory lock keyed by a hash of the name; blocks until free
+			if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", name); err != nil {
+				return nil, err
+			}
+			return func() {
+				conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", name)
+			}, nil
+		},
+	})
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLite (pure Go, no cgo) is always available so services can run with
+// DB_DRIVER=sqlite DB_PATH=:memory: for local development and tests.
+// Its LIKE is case-insensitive for ASCII, matching MySQL.
+func init() {
+	RegisterDialect(&Dialect{
+		Name:          "sqlite",
+		DriverName:    "sqlite",
+		TimestampType: "DATETIME",
+		IsUniqueViolation: func(err error) bool {
+			var sqliteErr *sqlite.Error
+			if !errors.As(err, &sqliteErr) {
+				return false
+			}
+			code := sqliteErr.Code()
+			return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+		},
+		Lock: func(ctx context.Context, conn *sql.Conn, name string) (func(), error) {
+			// The pool holds a single connection; other processes sharing the
+			// file are serialized by SQLite's own write lock
+			return func() {}, nil
+		},
+		SingleConnection: true,
+	})
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebind(t *testing.T) {
+	defer func(d *Dialect) { currentDialect = d }(currentDialect)
+
+	tests := []struct {
+		dialect string
+		query   string
+		want    string
+	}{
+		{"postgres", `SELECT "title" FROM "User" WHERE "id" = ? AND "status" IN (?, ?)`, `SELECT "title" FROM "User" WHERE "id" = $1 AND "status" IN ($2, $3)`},
+		{"postgres", `SELECT * FROM "User" WHERE "id" = $1 AND "title" LIKE ? ESCAPE '!' AND note = '?'`, `SELECT * FROM "User" WHERE "id" = $1 AND "title" LIKE $2 ESCAPE '!' AND note = '?'`},
+		{"mysql", `SELECT "title" FROM "User" WHERE "id" = ? AND note = 'say "hi"'`, "SELECT `title` FROM `User` WHERE `id` = ? AND note = 'say \"hi\"'"},
+		{"mysql", "SELECT `title` FROM `User` WHERE `id` = ?", "SELECT `title` FROM `User` WHERE `id` = ?"},
+		{"sqlite", `SELECT "title" FROM "User" WHERE "id" = ?`, `SELECT "title" FROM "User" WHERE "id" = ?`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			// Projects only get the dialects they were created with
+			if _, ok := dialects[tt.dialect]; !ok {
+				t.Skipf("dialect %s is not registered", tt.dialect)
+			}
+			assert.NoError(t, SetDialect(tt.dialect))
+			assert.Equal(t, tt.want, Rebind(tt.query))
+		})
+	}
+}
+
+func TestUnsupportedDriver(t *testing.T) {
+	defer func(d *Dialect) { currentDialect = d }(currentDialect)
+	currentDialect = nil
+	t.Setenv("DB_DRIVER", "oracle")
+
+	// Connect reports the driver; the dialect helpers keep working
+	_, err := Connect("")
+	assert.ErrorContains(t, err, `unsupported DB_DRIVER "oracle"`)
+	assert.NotPanics(t, func() { Rebind(`SELECT "id" FROM "User"`) })
+	assert.Equal(t, DefaultDriver, CurrentDialect().Name)
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000002_add_phone.up.sql":            {Data: []byte("ALTER TABLE User ADD COLUMN phone VARCHAR(255) NULL;")},
+		"000002_add_phone.down.sql":          {Data: []byte("ALTER TABLE User DROP COLUMN phone;")},
+		"000001_create_user_tables.up.sql":   {Data: []byte("CREATE TABLE User (id VARCHAR(36) NOT NULL);")},
+		"000001_create_user_tables.down.sql": {Data: []byte("DROP TABLE IF EXISTS User;")},
+		"embed.go":                           {Data: []byte("package migrations")},
+	}
+
+	migrations, err := LoadMigrations(fsys)
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "create_user_tables", migrations[0].Name)
+	assert.Equal(t, "DROP TABLE IF EXISTS User;", migrations[0].Down)
+	assert.Equal(t, int64(2), migrations[1].Version)
+	assert.Equal(t, "add_phone", migrations[1].Name)
+}
+
+func TestLoadMigrationsConflictingNames(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000001_a.up.sql":   {Data: []byte("SELECT 1;")},
+		"000001_b.down.sql": {Data: []byte("SELECT 1;")},
+	}
+
+	_, err := LoadMigrations(fsys)
+	assert.Error(t, err)
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- create tables
+CREATE TABLE a (
+  status ENUM('x;y', 'z') NOT NULL
+);
+
+ALTER TABLE a ADD COLUMN b INT NULL;
+`
+	assert.Equal(t, []string{
+		"CREATE TABLE a (\n  status ENUM('x;y', 'z') NOT NULL\n)",
+		"ALTER TABLE a ADD COLUMN b INT NULL",
+	}, splitStatements(script))
+}
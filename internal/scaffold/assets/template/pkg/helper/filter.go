@@ -1,17 +1,70 @@
 package helper
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	pbCommon "thaily/proto/common"
 )
 
-// BuildFilterCondition builds SQL WHERE condition from FilterCondition (MySQL syntax with ?)
+// likeOperator is the operator of the LIKE filters (LIKE, NOT_LIKE,
+// STARTS_WITH, ENDS_WITH); PostgreSQL uses ILIKE so they stay case-insensitive
+// as they are on MySQL and SQLite
+var likeOperator = "LIKE"
+
+// likeEscape escapes the LIKE wildcards of filter values. It is not a
+// backslash, which MySQL and PostgreSQL string literals disagree on.
+const likeEscape = "!"
+
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// nullsSmallest tells where NULL sorts without NULLS_FIRST/LAST: before every
+// value on MySQL and SQLite, after every value on PostgreSQL
+var nullsSmallest = true
+
+// sqliteTimes is set on SQLite, which stores timestamps as text compared as
+// strings (see timeArg)
+var sqliteTimes = false
+
+// SetDialect adapts the generated SQL to the database (DB_DRIVER value).
+// Placeholders are always ? and identifiers are always double-quoted; the
+// generated handlers pass every query through database.Rebind, which
+// converts both for the database, and other callers must do the same.
+func SetDialect(name string) {
+	switch name {
+	case "postgres":
+		likeOperator = "ILIKE"
+	default:
+		likeOperator = "LIKE"
+	}
+	nullsSmallest = name != "postgres"
+	sqliteTimes = name == "sqlite"
+}
+
+// quoteIdent quotes a table or column name (each part of schema.table), so
+// names such as "user" or "order" that are reserved words can be used
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, ".", `"."`) + `"`
+}
+
+// BuildFilterCondition builds SQL WHERE condition from FilterCondition (? placeholders)
 func BuildFilterCondition(condition *pbCommon.FilterCondition, args *[]interface{}) string {
-	field := condition.Field
-	operator := condition.Operator
-	values := condition.Values
+	return BuildFilterConditionOnColumn(condition, condition.Field, args)
+}
 
+// BuildFilterConditionOnColumn builds the condition against column instead of
+// condition.Field (for fields stored under a different column name)
+func BuildFilterConditionOnColumn(condition *pbCommon.FilterCondition, column string, args *[]interface{}) string {
+	values := make([]interface{}, len(condition.Values))
+	for i, value := range condition.Values {
+		values[i] = value
+	}
+	return buildCondition(condition.Operator, column, values, args)
+}
+
+// buildCondition builds the condition of operator on field with values
+// (parsed or not)
+func buildCondition(operator pbCommon.FilterOperator, field string, values []interface{}, args *[]interface{}) string {
 	switch operator {
 	case pbCommon.FilterOperator_EQUAL:
 		*args = append(*args, values[0])
@@ -32,8 +85,16 @@ func BuildFilterCondition(condition *pbCommon.FilterCondition, args *[]interface
 		*args = append(*args, values[0])
 		return fmt.Sprintf("%s <= ?", field)
 	case pbCommon.FilterOperator_LIKE:
-		*args = append(*args, "%"+values[0]+"%")
-		return fmt.Sprintf("%s LIKE ?", field)
+		return likeCondition(field, "", "%"+escapeLike(values[0])+"%", args)
+	case pbCommon.FilterOperator_NOT_LIKE:
+		return likeCondition(field, "NOT ", "%"+escapeLike(values[0])+"%", args)
+	case pbCommon.FilterOperator_STARTS_WITH:
+		return likeCondition(field, "", escapeLike(values[0])+"%", args)
+	case pbCommon.FilterOperator_ENDS_WITH:
+		return likeCondition(field, "", "%"+escapeLike(values[0]), args)
+	case pbCommon.FilterOperator_CASE_INSENSITIVE_EQUAL:
+		*args = append(*args, values[0])
+		return fmt.Sprintf("LOWER(%s) = LOWER(?)", field)
 	case pbCommon.FilterOperator_IN:
 		placeholders := []string{}
 		for _, val := range values {
@@ -57,11 +118,29 @@ func BuildFilterCondition(condition *pbCommon.FilterCondition, args *[]interface
 			*args = append(*args, values[0], values[1])
 			return fmt.Sprintf("%s BETWEEN ? AND ?", field)
 		}
+	case pbCommon.FilterOperator_NOT_BETWEEN:
+		if len(values) >= 2 {
+			*args = append(*args, values[0], values[1])
+			return fmt.Sprintf("%s NOT BETWEEN ? AND ?", field)
+		}
 	}
 
 	return "1=1" // fallback
 }
 
+// likeCondition matches field against pattern, whose wildcards are escaped
+// with likeEscape; not is "" or "NOT "
+func likeCondition(field, not, pattern string, args *[]interface{}) string {
+	*args = append(*args, pattern)
+	return fmt.Sprintf("%s %s%s ? ESCAPE '%s'", field, not, likeOperator, likeEscape)
+}
+
+// escapeLike returns value as a LIKE pattern matching only value itself, so
+// a filter on "50%" does not match every value starting with "50"
+func escapeLike(value interface{}) string {
+	return likeEscaper.Replace(fmt.Sprint(value))
+}
+
 // BuildFilterGroup builds SQL WHERE condition from FilterGroup with nested support
 func BuildFilterGroup(group *pbCommon.FilterGroup, args *[]interface{}) string {
 	if group == nil || len(group.Filters) == 0 {
@@ -190,3 +269,149 @@ func BuildWhereClause(filters []*pbCommon.FilterCriteria, args *[]interface{}, w
 
 	return "WHERE " + strings.Join(whereConditions, " AND ")
 }
+
+// FilterLimits bound the criteria tree a request may send, so a single List
+// call cannot make the database evaluate an arbitrarily large WHERE clause
+type FilterLimits struct {
+	MaxDepth      int // nesting of groups (top-level criteria are depth 0)
+	MaxConditions int // conditions in the whole tree
+	MaxValues     int // values of one condition (IN, NOT_IN)
+}
+
+// DefaultFilterLimits are the limits of generated List handlers
+var DefaultFilterLimits = FilterLimits{MaxDepth: 4, MaxConditions: 32, MaxValues: 100}
+
+// BuildWhere builds the WHERE clause ("" without filters) of a List request:
+// top-level criteria are ANDed, groups are joined with their logic and may be
+// nested. Fields are translated to columns through columns, the whitelist of
+// the handler, which also parses their values. Unlike BuildWhereClause nothing
+// is skipped: an unknown field, a malformed condition, a value of the wrong
+// type or a tree beyond limits is an error the handler returns as
+// InvalidArgument. Columns are quoted and placeholders are ?: pass the query
+// through database.Rebind.
+func BuildWhere(filters []*pbCommon.FilterCriteria, columns map[string]FilterColumn, limits FilterLimits, args *[]interface{}) (string, error) {
+	b := &whereBuilder{columns: columns, limits: limits, args: args}
+	conditions := []string{}
+	for _, filter := range filters {
+		condition, err := b.criteria(filter, 0)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, condition)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), nil
+}
+
+type whereBuilder struct {
+	columns    map[string]FilterColumn
+	limits     FilterLimits
+	args       *[]interface{}
+	conditions int
+}
+
+func (b *whereBuilder) criteria(criteria *pbCommon.FilterCriteria, depth int) (string, error) {
+	if condition := criteria.GetCondition(); condition != nil {
+		return b.condition(condition)
+	}
+	if group := criteria.GetGroup(); group != nil {
+		return b.group(group, depth+1)
+	}
+	return "", errors.New("filter without a condition or a group")
+}
+
+func (b *whereBuilder) group(group *pbCommon.FilterGroup, depth int) (string, error) {
+	if depth > b.limits.MaxDepth {
+		return "", fmt.Errorf("filter groups are nested deeper than %d levels", b.limits.MaxDepth)
+	}
+	if len(group.Filters) == 0 {
+		return "", errors.New("empty filter group")
+	}
+
+	conditions := []string{}
+	for _, filter := range group.Filters {
+		condition, err := b.criteria(filter, depth)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, condition)
+	}
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+
+	logicOp := "AND"
+	if group.Logic == pbCommon.LogicalCondition_OR {
+		logicOp = "OR"
+	}
+	return "(" + strings.Join(conditions, " "+logicOp+" ") + ")", nil
+}
+
+func (b *whereBuilder) condition(condition *pbCommon.FilterCondition) (string, error) {
+	if b.conditions++; b.conditions > b.limits.MaxConditions {
+		return "", fmt.Errorf("more than %d filter conditions", b.limits.MaxConditions)
+	}
+	column, ok := b.columns[condition.Field]
+	if !ok {
+		return "", fmt.Errorf("cannot filter by %q", condition.Field)
+	}
+	if err := checkValues(condition, b.limits.MaxValues); err != nil {
+		return "", fmt.Errorf("filter on %q: %w", condition.Field, err)
+	}
+
+	if textOperators[condition.Operator] && column.Kind != StringValue {
+		return "", fmt.Errorf("filter on %q: %s only applies to text fields", condition.Field, condition.Operator)
+	}
+
+	values := make([]interface{}, len(condition.Values))
+	for i, value := range condition.Values {
+		parsed, err := column.Parse(value)
+		if err != nil {
+			return "", fmt.Errorf("filter on %q: %w", condition.Field, err)
+		}
+		values[i] = parsed
+	}
+	return buildCondition(condition.Operator, quoteIdent(column.Column), values, b.args), nil
+}
+
+// textOperators compare text, so they only apply to StringValue columns
+var textOperators = map[pbCommon.FilterOperator]bool{
+	pbCommon.FilterOperator_LIKE:                   true,
+	pbCommon.FilterOperator_NOT_LIKE:               true,
+	pbCommon.FilterOperator_STARTS_WITH:            true,
+	pbCommon.FilterOperator_ENDS_WITH:              true,
+	pbCommon.FilterOperator_CASE_INSENSITIVE_EQUAL: true,
+}
+
+// checkValues checks a condition has the number of values its operator takes
+func checkValues(condition *pbCommon.FilterCondition, maxValues int) error {
+	n := len(condition.Values)
+	switch condition.Operator {
+	case pbCommon.FilterOperator_EQUAL, pbCommon.FilterOperator_NOT_EQUAL,
+		pbCommon.FilterOperator_GREATER_THAN, pbCommon.FilterOperator_GREATER_THAN_EQUAL,
+		pbCommon.FilterOperator_LESS_THAN, pbCommon.FilterOperator_LESS_THAN_EQUAL,
+		pbCommon.FilterOperator_LIKE, pbCommon.FilterOperator_NOT_LIKE,
+		pbCommon.FilterOperator_STARTS_WITH, pbCommon.FilterOperator_ENDS_WITH,
+		pbCommon.FilterOperator_CASE_INSENSITIVE_EQUAL:
+		if n != 1 {
+			return fmt.Errorf("%s takes 1 value, got %d", condition.Operator, n)
+		}
+	case pbCommon.FilterOperator_IN, pbCommon.FilterOperator_NOT_IN:
+		if n == 0 || n > maxValues {
+			return fmt.Errorf("%s takes 1 to %d values, got %d", condition.Operator, maxValues, n)
+		}
+	case pbCommon.FilterOperator_IS_NULL, pbCommon.FilterOperator_IS_NOT_NULL:
+		if n != 0 {
+			return fmt.Errorf("%s takes no values, got %d", condition.Operator, n)
+		}
+	case pbCommon.FilterOperator_BETWEEN, pbCommon.FilterOperator_NOT_BETWEEN:
+		if n != 2 {
+			return fmt.Errorf("%s takes 2 values, got %d", condition.Operator, n)
+		}
+	default:
+		return fmt.Errorf("unknown operator %d", condition.Operator)
+	}
+	return nil
+}
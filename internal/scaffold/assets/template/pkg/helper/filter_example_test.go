@@ -84,7 +84,7 @@ func TestYourExactJSONRequest(t *testing.T) {
 	t.Logf("======================================")
 
 	// Expected results
-	expectedWhereClause := "WHERE title LIKE ? AND title LIKE ?"
+	expectedWhereClause := "WHERE title LIKE ? ESCAPE '!' AND title LIKE ? ESCAPE '!'"
 	expectedArgs := []interface{}{"%10%", "%CNTT%"}
 
 	if whereClause != expectedWhereClause {
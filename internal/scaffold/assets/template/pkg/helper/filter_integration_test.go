@@ -72,7 +72,7 @@ func TestFilterIntegrationWithWhitelist(t *testing.T) {
 	}
 
 	// Expected results
-	expectedWhereClause := "WHERE title LIKE ? AND title LIKE ?"
+	expectedWhereClause := "WHERE title LIKE ? ESCAPE '!' AND title LIKE ? ESCAPE '!'"
 	expectedArgs := []interface{}{"%10%", "%CNTT%"}
 
 	assert.Equal(t, expectedWhereClause, whereClause, "WHERE clause should match")
@@ -157,7 +157,7 @@ func TestFilterIntegrationWithInvalidField(t *testing.T) {
 	}
 
 	// Should only include valid fields
-	expectedWhereClause := "WHERE title LIKE ? AND major_code = ?"
+	expectedWhereClause := "WHERE title LIKE ? ESCAPE '!' AND major_code = ?"
 	expectedArgs := []interface{}{"%10%", "CNTT"}
 
 	assert.Equal(t, expectedWhereClause, whereClause, "Invalid fields should be filtered out")
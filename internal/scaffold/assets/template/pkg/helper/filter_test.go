@@ -9,44 +9,124 @@ import (
 
 func TestBuildFilterCondition(t *testing.T) {
 	tests := []struct {
-		name          string
-		condition     *pbCommon.FilterCondition
-		expectedSQL   string
-		expectedArgs  []interface{}
+		name         string
+		condition    *pbCommon.FilterCondition
+		expectedSQL  string
+		expectedArgs []interface{}
 	}{
 		{
-			name: "LIKE operator",
-			condition: &pbCommon.FilterCondition{
-				Field:    "title",
-				Operator: pbCommon.FilterOperator_LIKE,
-				Values:   []string{"10"},
-			},
-			expectedSQL:  "title LIKE ?",
-			expectedArgs: []interface{}{"%10%"},
+			name:         "EQUAL operator",
+			condition:    &pbCommon.FilterCondition{Field: "status", Operator: pbCommon.FilterOperator_EQUAL, Values: []string{"active"}},
+			expectedSQL:  "status = ?",
+			expectedArgs: []interface{}{"active"},
 		},
 		{
-			name: "EQUAL operator",
-			condition: &pbCommon.FilterCondition{
-				Field:    "status",
-				Operator: pbCommon.FilterOperator_EQUAL,
-				Values:   []string{"active"},
-			},
-			expectedSQL:  "status = ?",
+			name:         "NOT_EQUAL operator",
+			condition:    &pbCommon.FilterCondition{Field: "status", Operator: pbCommon.FilterOperator_NOT_EQUAL, Values: []string{"active"}},
+			expectedSQL:  "status != ?",
 			expectedArgs: []interface{}{"active"},
 		},
 		{
-			name: "IN operator",
-			condition: &pbCommon.FilterCondition{
-				Field:    "major_code",
-				Operator: pbCommon.FilterOperator_IN,
-				Values:   []string{"CNTT", "KTPM"},
-			},
+			name:         "GREATER_THAN operator",
+			condition:    &pbCommon.FilterCondition{Field: "score", Operator: pbCommon.FilterOperator_GREATER_THAN, Values: []string{"5"}},
+			expectedSQL:  "score > ?",
+			expectedArgs: []interface{}{"5"},
+		},
+		{
+			name:         "GREATER_THAN_EQUAL operator",
+			condition:    &pbCommon.FilterCondition{Field: "score", Operator: pbCommon.FilterOperator_GREATER_THAN_EQUAL, Values: []string{"5"}},
+			expectedSQL:  "score >= ?",
+			expectedArgs: []interface{}{"5"},
+		},
+		{
+			name:         "LESS_THAN operator",
+			condition:    &pbCommon.FilterCondition{Field: "score", Operator: pbCommon.FilterOperator_LESS_THAN, Values: []string{"5"}},
+			expectedSQL:  "score < ?",
+			expectedArgs: []interface{}{"5"},
+		},
+		{
+			name:         "LESS_THAN_EQUAL operator",
+			condition:    &pbCommon.FilterCondition{Field: "score", Operator: pbCommon.FilterOperator_LESS_THAN_EQUAL, Values: []string{"5"}},
+			expectedSQL:  "score <= ?",
+			expectedArgs: []interface{}{"5"},
+		},
+		{
+			name:         "LIKE operator",
+			condition:    &pbCommon.FilterCondition{Field: "title", Operator: pbCommon.FilterOperator_LIKE, Values: []string{"10"}},
+			expectedSQL:  "title LIKE ? ESCAPE '!'",
+			expectedArgs: []interface{}{"%10%"},
+		},
+		{
+			name:         "LIKE escapes wildcards",
+			condition:    &pbCommon.FilterCondition{Field: "title", Operator: pbCommon.FilterOperator_LIKE, Values: []string{"50%_off!"}},
+			expectedSQL:  "title LIKE ? ESCAPE '!'",
+			expectedArgs: []interface{}{"%50!%!_off!!%"},
+		},
+		{
+			name:         "NOT_LIKE operator",
+			condition:    &pbCommon.FilterCondition{Field: "title", Operator: pbCommon.FilterOperator_NOT_LIKE, Values: []string{"10%"}},
+			expectedSQL:  "title NOT LIKE ? ESCAPE '!'",
+			expectedArgs: []interface{}{"%10!%%"},
+		},
+		{
+			name:         "STARTS_WITH operator",
+			condition:    &pbCommon.FilterCondition{Field: "code", Operator: pbCommon.FilterOperator_STARTS_WITH, Values: []string{"CN_"}},
+			expectedSQL:  "code LIKE ? ESCAPE '!'",
+			expectedArgs: []interface{}{"CN!_%"},
+		},
+		{
+			name:         "ENDS_WITH operator",
+			condition:    &pbCommon.FilterCondition{Field: "email", Operator: pbCommon.FilterOperator_ENDS_WITH, Values: []string{"@example.com"}},
+			expectedSQL:  "email LIKE ? ESCAPE '!'",
+			expectedArgs: []interface{}{"%@example.com"},
+		},
+		{
+			name:         "CASE_INSENSITIVE_EQUAL operator",
+			condition:    &pbCommon.FilterCondition{Field: "email", Operator: pbCommon.FilterOperator_CASE_INSENSITIVE_EQUAL, Values: []string{"A@Example.com"}},
+			expectedSQL:  "LOWER(email) = LOWER(?)",
+			expectedArgs: []interface{}{"A@Example.com"},
+		},
+		{
+			name:         "IN operator",
+			condition:    &pbCommon.FilterCondition{Field: "major_code", Operator: pbCommon.FilterOperator_IN, Values: []string{"CNTT", "KTPM"}},
 			expectedSQL:  "major_code IN (?, ?)",
 			expectedArgs: []interface{}{"CNTT", "KTPM"},
 		},
+		{
+			name:         "NOT_IN operator",
+			condition:    &pbCommon.FilterCondition{Field: "major_code", Operator: pbCommon.FilterOperator_NOT_IN, Values: []string{"CNTT"}},
+			expectedSQL:  "major_code NOT IN (?)",
+			expectedArgs: []interface{}{"CNTT"},
+		},
+		{
+			name:         "IS_NULL operator",
+			condition:    &pbCommon.FilterCondition{Field: "deleted_at", Operator: pbCommon.FilterOperator_IS_NULL},
+			expectedSQL:  "deleted_at IS NULL",
+			expectedArgs: []interface{}{},
+		},
+		{
+			name:         "IS_NOT_NULL operator",
+			condition:    &pbCommon.FilterCondition{Field: "deleted_at", Operator: pbCommon.FilterOperator_IS_NOT_NULL},
+			expectedSQL:  "deleted_at IS NOT NULL",
+			expectedArgs: []interface{}{},
+		},
+		{
+			name:         "BETWEEN operator",
+			condition:    &pbCommon.FilterCondition{Field: "score", Operator: pbCommon.FilterOperator_BETWEEN, Values: []string{"1", "5"}},
+			expectedSQL:  "score BETWEEN ? AND ?",
+			expectedArgs: []interface{}{"1", "5"},
+		},
+		{
+			name:         "NOT_BETWEEN operator",
+			condition:    &pbCommon.FilterCondition{Field: "score", Operator: pbCommon.FilterOperator_NOT_BETWEEN, Values: []string{"1", "5"}},
+			expectedSQL:  "score NOT BETWEEN ? AND ?",
+			expectedArgs: []interface{}{"1", "5"},
+		},
 	}
 
+	covered := make(map[pbCommon.FilterOperator]bool)
 	for _, tt := range tests {
+		covered[tt.condition.Operator] = true
 		t.Run(tt.name, func(t *testing.T) {
 			args := []interface{}{}
 			sql := BuildFilterCondition(tt.condition, &args)
@@ -55,6 +135,10 @@ func TestBuildFilterCondition(t *testing.T) {
 			assert.Equal(t, tt.expectedArgs, args)
 		})
 	}
+
+	for value, name := range pbCommon.FilterOperator_name {
+		assert.True(t, covered[pbCommon.FilterOperator(value)], "no test for filter operator %s", name)
+	}
 }
 
 func TestBuildNestedFilters(t *testing.T) {
@@ -132,8 +216,8 @@ func TestBuildNestedFilters(t *testing.T) {
 	// Với logic mới, cả 2 filters đều được xử lý
 	assert.Equal(t, 2, len(conditions2), "Logic mới xử lý được cả condition và group")
 	assert.Equal(t, []interface{}{"%10%", "%CNTT%"}, args2)
-	assert.Equal(t, "title LIKE ?", conditions2[0])
-	assert.Equal(t, "title LIKE ?", conditions2[1]) // Group chỉ có 1 filter nên không cần dấu ngoặc
+	assert.Equal(t, "title LIKE ? ESCAPE '!'", conditions2[0])
+	assert.Equal(t, "title LIKE ? ESCAPE '!'", conditions2[1]) // Group chỉ có 1 filter nên không cần dấu ngoặc
 }
 
 func TestBuildNestedFiltersWithProperImplementation(t *testing.T) {
@@ -204,3 +288,141 @@ func TestBuildNestedFiltersWithProperImplementation(t *testing.T) {
 	assert.Equal(t, "(major_code = ? OR major_code = ?)", conditions[1])
 	assert.Equal(t, []interface{}{"active", "CNTT", "KTPM"}, args)
 }
+
+func TestBuildFilterConditionPostgresLike(t *testing.T) {
+	SetDialect("postgres")
+	defer SetDialect("mysql")
+
+	args := []interface{}{}
+	sql := BuildFilterCondition(&pbCommon.FilterCondition{
+		Field:    "title",
+		Operator: pbCommon.FilterOperator_LIKE,
+		Values:   []string{"go"},
+	}, &args)
+
+	assert.Equal(t, "title ILIKE ? ESCAPE '!'", sql)
+	assert.Equal(t, []interface{}{"%go%"}, args)
+
+	args = []interface{}{}
+	sql = BuildFilterCondition(&pbCommon.FilterCondition{
+		Field:    "title",
+		Operator: pbCommon.FilterOperator_NOT_LIKE,
+		Values:   []string{"go"},
+	}, &args)
+
+	assert.Equal(t, "title NOT ILIKE ? ESCAPE '!'", sql)
+}
+
+func testCondition(field string, operator pbCommon.FilterOperator, values ...string) *pbCommon.FilterCriteria {
+	return &pbCommon.FilterCriteria{Criteria: &pbCommon.FilterCriteria_Condition{Condition: &pbCommon.FilterCondition{
+		Field:    field,
+		Operator: operator,
+		Values:   values,
+	}}}
+}
+
+func testGroup(logic pbCommon.LogicalCondition, filters ...*pbCommon.FilterCriteria) *pbCommon.FilterCriteria {
+	return &pbCommon.FilterCriteria{Criteria: &pbCommon.FilterCriteria_Group{Group: &pbCommon.FilterGroup{
+		Logic:   logic,
+		Filters: filters,
+	}}}
+}
+
+func TestBuildWhere(t *testing.T) {
+	columns := map[string]FilterColumn{"status": {Column: "status"}, "majorCode": {Column: "major_code"}}
+	active := testCondition("status", pbCommon.FilterOperator_EQUAL, "active")
+	cntt := testCondition("majorCode", pbCommon.FilterOperator_EQUAL, "CNTT")
+	ktpm := testCondition("majorCode", pbCommon.FilterOperator_EQUAL, "KTPM")
+
+	tests := []struct {
+		name         string
+		filters      []*pbCommon.FilterCriteria
+		expectedSQL  string
+		expectedArgs []interface{}
+	}{
+		{
+			name:         "no filters",
+			expectedSQL:  "",
+			expectedArgs: []interface{}{},
+		},
+		{
+			name:         "top-level conditions are ANDed",
+			filters:      []*pbCommon.FilterCriteria{active, cntt},
+			expectedSQL:  `WHERE "status" = ? AND "major_code" = ?`,
+			expectedArgs: []interface{}{"active", "CNTT"},
+		},
+		{
+			name:         "OR group",
+			filters:      []*pbCommon.FilterCriteria{active, testGroup(pbCommon.LogicalCondition_OR, cntt, ktpm)},
+			expectedSQL:  `WHERE "status" = ? AND ("major_code" = ? OR "major_code" = ?)`,
+			expectedArgs: []interface{}{"active", "CNTT", "KTPM"},
+		},
+		{
+			name: "nested groups",
+			filters: []*pbCommon.FilterCriteria{testGroup(pbCommon.LogicalCondition_OR,
+				testGroup(pbCommon.LogicalCondition_AND, active, cntt),
+				ktpm,
+			)},
+			expectedSQL:  `WHERE (("status" = ? AND "major_code" = ?) OR "major_code" = ?)`,
+			expectedArgs: []interface{}{"active", "CNTT", "KTPM"},
+		},
+		{
+			name:         "group of one condition",
+			filters:      []*pbCommon.FilterCriteria{testGroup(pbCommon.LogicalCondition_OR, cntt)},
+			expectedSQL:  `WHERE "major_code" = ?`,
+			expectedArgs: []interface{}{"CNTT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := []interface{}{}
+			sql, err := BuildWhere(tt.filters, columns, DefaultFilterLimits, &args)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedSQL, sql)
+			assert.Equal(t, tt.expectedArgs, args)
+		})
+	}
+}
+
+func TestBuildWhereErrors(t *testing.T) {
+	columns := map[string]FilterColumn{
+		"status":  {Column: "status"},
+		"credits": {Column: "credits", Kind: IntValue},
+		"role":    {Column: "role", Kind: EnumValue, Enum: []string{"admin"}},
+	}
+	limits := FilterLimits{MaxDepth: 2, MaxConditions: 3, MaxValues: 2}
+	active := testCondition("status", pbCommon.FilterOperator_EQUAL, "active")
+
+	tests := []struct {
+		name          string
+		filter        *pbCommon.FilterCriteria
+		expectedError string
+	}{
+		{"unknown field", testCondition("password", pbCommon.FilterOperator_EQUAL, "x"), `cannot filter by "password"`},
+		{"unknown field in group", testGroup(pbCommon.LogicalCondition_OR, active, testCondition("password", pbCommon.FilterOperator_EQUAL, "x")), `cannot filter by "password"`},
+		{"neither condition nor group", &pbCommon.FilterCriteria{}, "filter without a condition or a group"},
+		{"empty group", testGroup(pbCommon.LogicalCondition_AND), "empty filter group"},
+		{"too deep", testGroup(pbCommon.LogicalCondition_AND, testGroup(pbCommon.LogicalCondition_AND, testGroup(pbCommon.LogicalCondition_AND, active))), "nested deeper than 2 levels"},
+		{"too many conditions", testGroup(pbCommon.LogicalCondition_OR, active, active, active, active), "more than 3 filter conditions"},
+		{"too many values", testCondition("status", pbCommon.FilterOperator_IN, "a", "b", "c"), "IN takes 1 to 2 values, got 3"},
+		{"missing value", testCondition("status", pbCommon.FilterOperator_EQUAL), "EQUAL takes 1 value, got 0"},
+		{"BETWEEN with one value", testCondition("status", pbCommon.FilterOperator_BETWEEN, "a"), "BETWEEN takes 2 values, got 1"},
+		{"NOT_BETWEEN with three values", testCondition("status", pbCommon.FilterOperator_NOT_BETWEEN, "a", "b", "c"), "NOT_BETWEEN takes 2 values, got 3"},
+		{"STARTS_WITH without a value", testCondition("status", pbCommon.FilterOperator_STARTS_WITH), "STARTS_WITH takes 1 value, got 0"},
+		{"IS_NULL with a value", testCondition("status", pbCommon.FilterOperator_IS_NULL, "a"), "IS_NULL takes no values, got 1"},
+		{"unknown operator", testCondition("status", pbCommon.FilterOperator(99), "a"), "unknown operator 99"},
+		{"LIKE on an integer", testCondition("credits", pbCommon.FilterOperator_LIKE, "3"), `filter on "credits": LIKE only applies to text fields`},
+		{"CASE_INSENSITIVE_EQUAL on an enum", testCondition("role", pbCommon.FilterOperator_CASE_INSENSITIVE_EQUAL, "Admin"), `filter on "role": CASE_INSENSITIVE_EQUAL only applies to text fields`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := []interface{}{}
+			_, err := BuildWhere([]*pbCommon.FilterCriteria{tt.filter}, columns, limits, &args)
+
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
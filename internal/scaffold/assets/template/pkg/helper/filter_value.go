@@ -0,0 +1,86 @@
+package helper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueKind is the type filter values of a column are parsed as
+type ValueKind int
+
+const (
+	StringValue    ValueKind = iota // string and bytes fields, passed as is
+	IntValue                        // signed integers
+	UintValue                       // unsigned integers
+	FloatValue                      // float and double
+	BoolValue                       // true/false, 1/0
+	TimestampValue                  // RFC 3339, e.g. 2025-01-02T15:04:05Z
+	EnumValue                       // stored enum value names, e.g. active
+)
+
+// FilterColumn is a field List handlers may filter by: its column and how
+// its filter values are parsed
+type FilterColumn struct {
+	Column string
+	Kind   ValueKind
+	// Enum lists the stored values of an EnumValue field: lower-case value
+	// names without the enum prefix (ENROLLMENT_STATUS_ACTIVE -> active)
+	Enum []string
+}
+
+// Parse converts a filter value to the argument compared with the column; an
+// unparsable value is an error naming the expected type
+func (c FilterColumn) Parse(value string) (interface{}, error) {
+	switch c.Kind {
+	case IntValue:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", value)
+		}
+		return n, nil
+	case UintValue:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an unsigned integer", value)
+		}
+		return n, nil
+	case FloatValue:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return f, nil
+	case BoolValue:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean (true or false)", value)
+		}
+		return b, nil
+	case TimestampValue:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an RFC 3339 timestamp (e.g. 2025-01-02T15:04:05Z)", value)
+		}
+		return timeArg(t), nil
+	case EnumValue:
+		for _, name := range c.Enum {
+			if strings.EqualFold(value, name) {
+				return strings.ToLower(name), nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not one of %s", value, strings.Join(c.Enum, ", "))
+	}
+	return value, nil
+}
+
+// timeArg returns t as an argument comparing with the stored timestamps:
+// SQLite stores them as "2006-01-02 15:04:05" UTC text (CURRENT_TIMESTAMP),
+// which a time.Time argument is not written as
+func timeArg(t time.Time) interface{} {
+	if sqliteTimes {
+		return t.UTC().Format("2006-01-02 15:04:05.999999999")
+	}
+	return t
+}
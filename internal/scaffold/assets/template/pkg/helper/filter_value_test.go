@@ -0,0 +1,97 @@
+package helper
+
+import (
+	"testing"
+	"time"
+	pbCommon "thaily/proto/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterColumnParse(t *testing.T) {
+	status := FilterColumn{Column: "status", Kind: EnumValue, Enum: []string{"active", "inactive"}}
+
+	tests := []struct {
+		name     string
+		column   FilterColumn
+		value    string
+		expected interface{}
+	}{
+		{"string", FilterColumn{Kind: StringValue}, "42", "42"},
+		{"int", FilterColumn{Kind: IntValue}, "-42", int64(-42)},
+		{"uint", FilterColumn{Kind: UintValue}, "42", uint64(42)},
+		{"float", FilterColumn{Kind: FloatValue}, "4.5", 4.5},
+		{"bool", FilterColumn{Kind: BoolValue}, "true", true},
+		{"bool digit", FilterColumn{Kind: BoolValue}, "0", false},
+		{"timestamp", FilterColumn{Kind: TimestampValue}, "2025-01-02T15:04:05+07:00", time.Date(2025, 1, 2, 15, 4, 5, 0, time.FixedZone("", 7*3600))},
+		{"enum upper case", status, "INACTIVE", "inactive"},
+		{"enum stored value", status, "inactive", "inactive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := tt.column.Parse(tt.value)
+
+			assert.NoError(t, err)
+			if expected, ok := tt.expected.(time.Time); ok {
+				assert.True(t, expected.Equal(value.(time.Time)), "got %v", value)
+				return
+			}
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestFilterColumnParseErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		column        FilterColumn
+		value         string
+		expectedError string
+	}{
+		{"int", FilterColumn{Kind: IntValue}, "4.5", `"4.5" is not an integer`},
+		{"uint", FilterColumn{Kind: UintValue}, "-1", `"-1" is not an unsigned integer`},
+		{"float", FilterColumn{Kind: FloatValue}, "abc", `"abc" is not a number`},
+		{"bool", FilterColumn{Kind: BoolValue}, "yes", `"yes" is not a boolean`},
+		{"timestamp", FilterColumn{Kind: TimestampValue}, "2025-01-02", `"2025-01-02" is not an RFC 3339 timestamp`},
+		{"enum", FilterColumn{Kind: EnumValue, Enum: []string{"active", "inactive"}}, "DELETED", `"DELETED" is not one of active, inactive`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.column.Parse(tt.value)
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestBuildWhereParsesValues(t *testing.T) {
+	columns := map[string]FilterColumn{
+		"credits": {Column: "credits", Kind: IntValue},
+		"status":  {Column: "status", Kind: EnumValue, Enum: []string{"active", "inactive"}},
+	}
+	filters := []*pbCommon.FilterCriteria{
+		testCondition("credits", pbCommon.FilterOperator_BETWEEN, "3", "5"),
+		testCondition("status", pbCommon.FilterOperator_IN, "ACTIVE", "inactive"),
+	}
+
+	args := []interface{}{}
+	sql, err := BuildWhere(filters, columns, DefaultFilterLimits, &args)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `WHERE "credits" BETWEEN ? AND ? AND "status" IN (?, ?)`, sql)
+	assert.Equal(t, []interface{}{int64(3), int64(5), "active", "inactive"}, args)
+
+	_, err = BuildWhere([]*pbCommon.FilterCriteria{testCondition("credits", pbCommon.FilterOperator_EQUAL, "three")}, columns, DefaultFilterLimits, &args)
+	assert.EqualError(t, err, `filter on "credits": "three" is not an integer`)
+}
+
+func TestFilterColumnParseSQLiteTimes(t *testing.T) {
+	SetDialect("sqlite")
+	defer SetDialect("mysql")
+
+	value, err := FilterColumn{Kind: TimestampValue}.Parse("2025-01-02T15:04:05+07:00")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-01-02 08:04:05", value)
+}
@@ -0,0 +1,174 @@
+package helper
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	pbCommon "thaily/proto/common"
+)
+
+// pageTokenKey signs page tokens. It is random unless PAGE_TOKEN_SECRET is
+// set, so tokens of a service without the secret only work until it restarts
+// and only on the replica that issued them.
+var pageTokenKey = make([]byte, sha256.Size)
+
+func init() {
+	if _, err := rand.Read(pageTokenKey); err != nil {
+		panic(fmt.Sprintf("failed to generate page token key: %v", err))
+	}
+	// Sort key values may be timestamps
+	gob.Register(time.Time{})
+}
+
+// SetPageTokenKey signs page tokens with secret (PAGE_TOKEN_SECRET), so every
+// replica of a service accepts the tokens of the others; an empty secret
+// keeps the random key
+func SetPageTokenKey(secret string) {
+	if secret == "" {
+		return
+	}
+	key := sha256.Sum256([]byte(secret))
+	pageTokenKey = key[:]
+}
+
+// pageCursor is the content of a page token: the sort key values of the last
+// row of a page, and the scope of the request it was issued for
+type pageCursor struct {
+	Scope  string
+	Values []interface{}
+}
+
+// PageTokenScope identifies the rows a List request pages through: its table,
+// filters and sort keys. A token is only accepted by requests of its scope.
+func PageTokenScope(table, whereClause string, args []interface{}, keys []SortKey) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%#v\n", table, whereClause, args)
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s %t %d\n", key.Column, key.Descending, key.Nulls)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// EncodePageToken returns the opaque, signed token of the page after the row
+// whose sort key values are values
+func EncodePageToken(scope string, values []interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(pageCursor{Scope: scope, Values: values}); err != nil {
+		return "", fmt.Errorf("failed to encode page token: %w", err)
+	}
+	mac := hmac.New(sha256.New, pageTokenKey)
+	mac.Write(buf.Bytes())
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(buf.Bytes())), nil
+}
+
+// DecodePageToken returns the sort key values of a token issued by
+// EncodePageToken for scope. Tokens that were altered, signed with another
+// key or issued for another scope are errors the handler returns as
+// InvalidArgument.
+func DecodePageToken(token, scope string, keys int) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) < sha256.Size {
+		return nil, errors.New("malformed page token")
+	}
+	payload, sum := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	mac := hmac.New(sha256.New, pageTokenKey)
+	mac.Write(payload)
+	if !hmac.Equal(sum, mac.Sum(nil)) {
+		return nil, errors.New("page token signature mismatch")
+	}
+
+	var cursor pageCursor
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&cursor); err != nil {
+		return nil, errors.New("malformed page token")
+	}
+	if cursor.Scope != scope || len(cursor.Values) != keys {
+		return nil, errors.New("page token was issued for other filters or sort keys")
+	}
+	return cursor.Values, nil
+}
+
+// BuildSeek builds the condition selecting the rows after the row whose sort
+// key values are values, in the order of keys (which end with IDField), with
+// quoted columns and ? placeholders for database.Rebind
+func BuildSeek(keys []SortKey, values []interface{}, args *[]interface{}) string {
+	alternatives := []string{}
+	for i, key := range keys {
+		after, afterArgs := seekAfter(key, values[i])
+		if after == "" {
+			continue
+		}
+
+		// The keys before key are equal to those of the row, key comes after
+		conditions := []string{}
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				conditions = append(conditions, quoteIdent(keys[j].Column)+" IS NULL")
+			} else {
+				conditions = append(conditions, quoteIdent(keys[j].Column)+" = ?")
+				*args = append(*args, seekValue(values[j]))
+			}
+		}
+		conditions = append(conditions, after)
+		*args = append(*args, afterArgs...)
+		alternatives = append(alternatives, strings.Join(conditions, " AND "))
+	}
+
+	if len(alternatives) == 0 {
+		return "1=0"
+	}
+	return "((" + strings.Join(alternatives, ") OR (") + "))"
+}
+
+// seekAfter returns the condition on key of the values after value, "" when
+// none is
+func seekAfter(key SortKey, value interface{}) (string, []interface{}) {
+	column := quoteIdent(key.Column)
+	nullsFirst := nullsSortFirst(key)
+	if value == nil {
+		if nullsFirst {
+			return column + " IS NOT NULL", nil
+		}
+		return "", nil
+	}
+
+	operator := ">"
+	if key.Descending {
+		operator = "<"
+	}
+	condition := fmt.Sprintf("%s %s ?", column, operator)
+	if !nullsFirst {
+		condition = fmt.Sprintf("(%s OR %s IS NULL)", condition, column)
+	}
+	return condition, []interface{}{seekValue(value)}
+}
+
+// nullsSortFirst reports whether NULL values of key come before the others
+func nullsSortFirst(key SortKey) bool {
+	switch key.Nulls {
+	case pbCommon.NullsOrder_NULLS_FIRST:
+		return true
+	case pbCommon.NullsOrder_NULLS_LAST:
+		return false
+	}
+	return nullsSmallest != key.Descending
+}
+
+// seekValue returns a sort key value read from the database as an argument
+// comparing equal to the stored value
+func seekValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return timeArg(v)
+	}
+	return value
+}
@@ -0,0 +1,127 @@
+package helper
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+	pbCommon "thaily/proto/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageToken(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	values := []interface{}{"title", nil, int64(3), createdAt, "id-1"}
+
+	token, err := EncodePageToken("scope", values)
+	assert.NoError(t, err)
+
+	decoded, err := DecodePageToken(token, "scope", len(values))
+	assert.NoError(t, err)
+	assert.Equal(t, values, decoded)
+
+	data, _ := base64.RawURLEncoding.DecodeString(token)
+	data[0] ^= 1
+	tampered := base64.RawURLEncoding.EncodeToString(data)
+
+	defer func(key []byte) { pageTokenKey = key }(pageTokenKey)
+	SetPageTokenKey("secret")
+	otherKey, err := EncodePageToken("scope", values)
+	assert.NoError(t, err)
+	SetPageTokenKey("other secret")
+
+	tests := []struct {
+		name          string
+		token         string
+		expectedError string
+	}{
+		{"not base64", "%%%", "malformed page token"},
+		{"too short", "abcd", "malformed page token"},
+		{"tampered", tampered, "signature mismatch"},
+		{"signed with another key", otherKey, "signature mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodePageToken(tt.token, "scope", len(values))
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+
+	token, err = EncodePageToken("scope", values)
+	assert.NoError(t, err)
+	_, err = DecodePageToken(token, "other scope", len(values))
+	assert.ErrorContains(t, err, "issued for other filters or sort keys")
+	_, err = DecodePageToken(token, "scope", len(values)+1)
+	assert.ErrorContains(t, err, "issued for other filters or sort keys")
+}
+
+func TestBuildSeek(t *testing.T) {
+	title := SortKey{Field: "title", Column: "topic_title"}
+	id := SortKey{Field: "id", Column: "id"}
+
+	tests := []struct {
+		name         string
+		keys         []SortKey
+		values       []interface{}
+		expectedSQL  string
+		expectedArgs []interface{}
+	}{
+		{
+			name:         "ascending",
+			keys:         []SortKey{title, id},
+			values:       []interface{}{"go", "id-1"},
+			expectedSQL:  `(("topic_title" > ?) OR ("topic_title" = ? AND "id" > ?))`,
+			expectedArgs: []interface{}{"go", "go", "id-1"},
+		},
+		{
+			name:         "descending, NULL last",
+			keys:         []SortKey{{Field: "title", Column: "topic_title", Descending: true}, id},
+			values:       []interface{}{"go", "id-1"},
+			expectedSQL:  `((("topic_title" < ? OR "topic_title" IS NULL)) OR ("topic_title" = ? AND "id" > ?))`,
+			expectedArgs: []interface{}{"go", "go", "id-1"},
+		},
+		{
+			name:         "NULL value sorted first",
+			keys:         []SortKey{{Field: "title", Column: "topic_title", Nulls: pbCommon.NullsOrder_NULLS_FIRST}, id},
+			values:       []interface{}{nil, "id-1"},
+			expectedSQL:  `(("topic_title" IS NOT NULL) OR ("topic_title" IS NULL AND "id" > ?))`,
+			expectedArgs: []interface{}{"id-1"},
+		},
+		{
+			name:         "NULL value sorted last",
+			keys:         []SortKey{{Field: "title", Column: "topic_title", Nulls: pbCommon.NullsOrder_NULLS_LAST}, id},
+			values:       []interface{}{nil, "id-1"},
+			expectedSQL:  `(("topic_title" IS NULL AND "id" > ?))`,
+			expectedArgs: []interface{}{"id-1"},
+		},
+		{
+			name:         "bytes",
+			keys:         []SortKey{id},
+			values:       []interface{}{[]byte("id-1")},
+			expectedSQL:  `(("id" > ?))`,
+			expectedArgs: []interface{}{"id-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := []interface{}{}
+			sql := BuildSeek(tt.keys, tt.values, &args)
+
+			assert.Equal(t, tt.expectedSQL, sql)
+			assert.Equal(t, tt.expectedArgs, args)
+		})
+	}
+}
+
+func TestBuildSeekSQLiteTimes(t *testing.T) {
+	SetDialect("sqlite")
+	defer SetDialect("mysql")
+
+	args := []interface{}{}
+	sql := BuildSeek([]SortKey{{Field: "created_at", Column: "created_at", Descending: true}},
+		[]interface{}{time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}, &args)
+
+	assert.Equal(t, `((("created_at" < ? OR "created_at" IS NULL)))`, sql)
+	assert.Equal(t, []interface{}{"2025-01-02 03:04:05"}, args)
+}
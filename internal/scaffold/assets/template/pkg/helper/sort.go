@@ -0,0 +1,104 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+	pbCommon "thaily/proto/common"
+)
+
+// IDField is the field (and column) that breaks ties between sort keys, so
+// rows with equal keys keep the same order from one page to the next
+const IDField = "id"
+
+// SortKey is one key of the ORDER BY of a List request
+type SortKey struct {
+	Field      string // field of the request
+	Column     string // column it is stored in
+	Descending bool
+	Nulls      pbCommon.NullsOrder
+}
+
+// ParseSort returns the sort keys of pagination: its sort specs, or sort_by
+// and descending when it has none, or def without either. Fields are
+// translated to columns through columns (field -> column), the whitelist of
+// the handler; an unknown or repeated field is an error the handler returns
+// as InvalidArgument. The keys always end with IDField.
+func ParseSort(pagination *pbCommon.Pagination, columns map[string]string, def SortKey) ([]SortKey, error) {
+	specs := pagination.GetSort()
+	if len(specs) > 0 && pagination.GetSortBy() != "" {
+		return nil, fmt.Errorf("use sort or sort_by, not both")
+	}
+	if len(specs) == 0 && pagination.GetSortBy() != "" {
+		direction := pbCommon.SortDirection_ASC
+		if pagination.GetDescending() {
+			direction = pbCommon.SortDirection_DESC
+		}
+		specs = []*pbCommon.SortSpec{{Field: pagination.GetSortBy(), Direction: direction}}
+	}
+
+	keys := []SortKey{}
+	if len(specs) == 0 {
+		keys = append(keys, def)
+	}
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		column, ok := columns[spec.Field]
+		if !ok {
+			return nil, fmt.Errorf("cannot sort by %q", spec.Field)
+		}
+		if seen[spec.Field] {
+			return nil, fmt.Errorf("%q is sorted by twice", spec.Field)
+		}
+		seen[spec.Field] = true
+		if _, ok := pbCommon.SortDirection_name[int32(spec.Direction)]; !ok {
+			return nil, fmt.Errorf("unknown sort direction %d", spec.Direction)
+		}
+		if _, ok := pbCommon.NullsOrder_name[int32(spec.Nulls)]; !ok {
+			return nil, fmt.Errorf("unknown nulls order %d", spec.Nulls)
+		}
+		keys = append(keys, SortKey{
+			Field:      spec.Field,
+			Column:     column,
+			Descending: spec.Direction == pbCommon.SortDirection_DESC,
+			Nulls:      spec.Nulls,
+		})
+	}
+
+	if keys[len(keys)-1].Field != IDField && !seen[IDField] {
+		keys = append(keys, SortKey{Field: IDField, Column: IDField})
+	}
+	return keys, nil
+}
+
+// BuildOrderBy builds the ORDER BY clause of keys. NULLS FIRST/LAST is
+// written as a CASE expression, which MySQL, PostgreSQL and SQLite all accept.
+// Columns are quoted for database.Rebind.
+func BuildOrderBy(keys []SortKey) string {
+	terms := []string{}
+	for _, key := range keys {
+		column := quoteIdent(key.Column)
+		switch key.Nulls {
+		case pbCommon.NullsOrder_NULLS_FIRST:
+			terms = append(terms, fmt.Sprintf("CASE WHEN %s IS NULL THEN 0 ELSE 1 END", column))
+		case pbCommon.NullsOrder_NULLS_LAST:
+			terms = append(terms, fmt.Sprintf("CASE WHEN %s IS NULL THEN 1 ELSE 0 END", column))
+		}
+
+		direction := "ASC"
+		if key.Descending {
+			direction = "DESC"
+		}
+		terms = append(terms, column+" "+direction)
+	}
+	return "ORDER BY " + strings.Join(terms, ", ")
+}
+
+// SortKeyColumns lists the columns of keys for a SELECT, which reads the sort
+// key values of the last row of a page for its next page token
+func SortKeyColumns(keys []SortKey) string {
+	columns := []string{}
+	for _, key := range keys {
+		columns = append(columns, quoteIdent(key.Column))
+	}
+	return strings.Join(columns, ", ")
+}
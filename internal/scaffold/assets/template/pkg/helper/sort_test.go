@@ -0,0 +1,85 @@
+package helper
+
+import (
+	"testing"
+	pbCommon "thaily/proto/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSort(t *testing.T) {
+	columns := map[string]string{"id": "id", "created_at": "created_at", "title": "topic_title"}
+	def := SortKey{Field: "created_at", Column: "created_at", Descending: true}
+
+	tests := []struct {
+		name        string
+		pagination  *pbCommon.Pagination
+		expectedSQL string
+	}{
+		{
+			name:        "default",
+			expectedSQL: `ORDER BY "created_at" DESC, "id" ASC`,
+		},
+		{
+			name:        "sort_by",
+			pagination:  &pbCommon.Pagination{SortBy: "title", Descending: true},
+			expectedSQL: `ORDER BY "topic_title" DESC, "id" ASC`,
+		},
+		{
+			name: "several keys",
+			pagination: &pbCommon.Pagination{Sort: []*pbCommon.SortSpec{
+				{Field: "title"},
+				{Field: "created_at", Direction: pbCommon.SortDirection_DESC},
+			}},
+			expectedSQL: `ORDER BY "topic_title" ASC, "created_at" DESC, "id" ASC`,
+		},
+		{
+			name: "nulls ordering",
+			pagination: &pbCommon.Pagination{Sort: []*pbCommon.SortSpec{
+				{Field: "title", Nulls: pbCommon.NullsOrder_NULLS_FIRST},
+				{Field: "created_at", Direction: pbCommon.SortDirection_DESC, Nulls: pbCommon.NullsOrder_NULLS_LAST},
+			}},
+			expectedSQL: `ORDER BY CASE WHEN "topic_title" IS NULL THEN 0 ELSE 1 END, "topic_title" ASC, ` +
+				`CASE WHEN "created_at" IS NULL THEN 1 ELSE 0 END, "created_at" DESC, "id" ASC`,
+		},
+		{
+			name:        "no tie-break after id",
+			pagination:  &pbCommon.Pagination{Sort: []*pbCommon.SortSpec{{Field: "id", Direction: pbCommon.SortDirection_DESC}}},
+			expectedSQL: `ORDER BY "id" DESC`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := ParseSort(tt.pagination, columns, def)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedSQL, BuildOrderBy(keys))
+		})
+	}
+}
+
+func TestParseSortErrors(t *testing.T) {
+	columns := map[string]string{"id": "id", "title": "topic_title"}
+
+	tests := []struct {
+		name          string
+		pagination    *pbCommon.Pagination
+		expectedError string
+	}{
+		{"unknown field", &pbCommon.Pagination{Sort: []*pbCommon.SortSpec{{Field: "password"}}}, `cannot sort by "password"`},
+		{"unknown sort_by", &pbCommon.Pagination{SortBy: "title; DROP TABLE topics"}, "cannot sort by"},
+		{"field twice", &pbCommon.Pagination{Sort: []*pbCommon.SortSpec{{Field: "title"}, {Field: "title"}}}, `"title" is sorted by twice`},
+		{"sort and sort_by", &pbCommon.Pagination{SortBy: "title", Sort: []*pbCommon.SortSpec{{Field: "title"}}}, "not both"},
+		{"unknown direction", &pbCommon.Pagination{Sort: []*pbCommon.SortSpec{{Field: "title", Direction: 5}}}, "unknown sort direction 5"},
+		{"unknown nulls order", &pbCommon.Pagination{Sort: []*pbCommon.SortSpec{{Field: "title", Nulls: 5}}}, "unknown nulls order 5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSort(tt.pagination, columns, SortKey{Field: "id", Column: "id"})
+
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
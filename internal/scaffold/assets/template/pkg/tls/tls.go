@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 // GetCertsPath returns the path to certs directory
@@ -91,6 +92,49 @@ func LoadServerTLSCredentials(serviceName string) (credentials.TransportCredenti
 	return credentials.NewTLS(tlsConfig), nil
 }
 
+// LoadServerCredentials returns the server credentials of a TLS mode:
+//   - "mtls" (or empty): LoadServerTLSCredentials, clients need a certificate
+//   - "tls": server certificate only, any client may connect
+//   - "insecure": plaintext, for local development only
+func LoadServerCredentials(serviceName, mode string) (credentials.TransportCredentials, error) {
+	switch mode {
+	case "insecure":
+		return insecure.NewCredentials(), nil
+	case "", "mtls", "tls":
+	default:
+		return nil, fmt.Errorf("invalid TLS_MODE %q (expected mtls, tls or insecure)", mode)
+	}
+
+	if err := VerifyCertificatesExist(serviceName); err != nil {
+		return nil, err
+	}
+	if mode == "tls" {
+		return LoadServerTLSCredentialsNoClientAuth(serviceName)
+	}
+	return LoadServerTLSCredentials(serviceName)
+}
+
+// LoadServerTLSCredentialsNoClientAuth loads server TLS credentials for
+// one-way TLS (clients verify the server, no client certificate)
+func LoadServerTLSCredentialsNoClientAuth(serviceName string) (credentials.TransportCredentials, error) {
+	basePath := GetServiceCertsPath(serviceName)
+
+	serverCert := filepath.Join(basePath, fmt.Sprintf("%s-server.crt", serviceName))
+	serverKey := filepath.Join(basePath, fmt.Sprintf("%s-server.key", serviceName))
+
+	certificate, err := tls.LoadX509KeyPair(serverCert, serverKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %v", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
 // LoadClientTLSCredentials loads client TLS credentials for mTLS
 // serverName should be the service name: user-service, council-service, etc.
 func LoadClientTLSCredentials(serverName string) (credentials.TransportCredentials, error) {
@@ -1,24 +1,11 @@
 package scaffold
 
-import (
-	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
-)
-
-func createCommonProto() error {
-	// Read module path
-	data, _ := os.ReadFile("go.mod")
-	modulePath := "mymodule"
-	for _, line := range strings.Split(string(data), "\n") {
-		if strings.HasPrefix(line, "module ") {
-			modulePath = strings.TrimSpace(strings.TrimPrefix(line, "module "))
-			break
-		}
-	}
-
-	content := fmt.Sprintf(`syntax = "proto3";
+import "fmt"
+
+// commonProto renders proto/common/common.proto, the filter, sort and
+// pagination messages the pkg helpers read
+func commonProto(modulePath string) []byte {
+	return []byte(fmt.Sprintf(`syntax = "proto3";
 
 package common;
 
@@ -38,6 +25,11 @@ enum FilterOperator {
   IS_NULL = 9;            // IS NULL
   IS_NOT_NULL = 10;       // IS NOT NULL
   BETWEEN = 11;           // BETWEEN val1 AND val2
+  STARTS_WITH = 12;       // LIKE value%%
+  ENDS_WITH = 13;         // LIKE %%value
+  CASE_INSENSITIVE_EQUAL = 14; // LOWER(field) = LOWER(value)
+  NOT_LIKE = 15;          // NOT LIKE %%value%%
+  NOT_BETWEEN = 16;       // NOT BETWEEN val1 AND val2
 }
 
 // ============= Logical Conditions =============
@@ -65,20 +57,72 @@ message FilterGroup {
   repeated FilterCriteria filters = 2;  // Nested filters (can be conditions or groups)
 }
 
+// ============= Sorting =============
+enum SortDirection {
+  ASC = 0;
+  DESC = 1;
+}
+
+enum NullsOrder {
+  NULLS_DEFAULT = 0;  // database default
+  NULLS_FIRST = 1;
+  NULLS_LAST = 2;
+}
+
+message SortSpec {
+  string field = 1;            // field to sort by
+  SortDirection direction = 2; // sort direction
+  NullsOrder nulls = 3;        // where NULL values go
+}
+
 // ============= Pagination =============
 message Pagination {
   int32 page = 1;           // page number (starting from 1)
   int32 page_size = 2;      // number of items per page
-  string sort_by = 3;       // field to sort by
+  string sort_by = 3;       // field to sort by (single key, use sort for more)
   bool descending = 4;      // sort direction (false = ASC, true = DESC)
+  repeated SortSpec sort = 5; // sort keys in order; id breaks the remaining ties
 }
 
 // ============= Generic Search Request =============
 message SearchRequest {
   Pagination pagination = 1;
   repeated FilterCriteria filters = 2;
+  string page_token = 3;               // next_page_token of the previous page (page is then ignored)
+  optional bool include_total = 4;     // count the matching rows (default: only without page_token)
+}
+`, modulePath))
 }
-`, modulePath)
 
-	return os.WriteFile(filepath.Join("proto", "common", "common.proto"), []byte(content), 0644)
+// optionsProto renders proto/grpcgen/options.proto, the custom options read
+// by the generator
+func optionsProto(modulePath string) []byte {
+	return []byte(fmt.Sprintf(`syntax = "proto3";
+
+// Custom options read by the skeleton generator.
+// Import "proto/grpcgen/options.proto" in a service proto to use them:
+//
+//   message Topic {
+//     option (grpcgen.table) = "topics";
+//     string title = 2 [(grpcgen.column) = "topic_title", (grpcgen.sortable) = true];
+//     string draft = 3 [(grpcgen.skip_db) = true];
+//   }
+package grpcgen;
+
+import "google/protobuf/descriptor.proto";
+
+option go_package = "%s/proto/grpcgen";
+
+extend google.protobuf.MessageOptions {
+  string table = 50001;       // table name (default: message name)
+}
+
+extend google.protobuf.FieldOptions {
+  string column = 50101;      // column name (default: snake_case field name)
+  bool skip_db = 50102;       // field is not stored in the database
+  bool filterable = 50103;    // List may filter by this field (default: true, false for timestamps)
+  bool sortable = 50104;      // List may sort by this field (default: true, false for timestamps)
+  bool index = 50105;         // gen-migration creates an index on the column
+}
+`, modulePath))
 }
@@ -0,0 +1,346 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the scaffold version of the projects this grpc-gen creates
+// (recorded in grpc-gen.yaml, see grpc-gen upgrade)
+const Version = "v0.4.0"
+
+// ProjectConfigFile is the project manifest: written by grpc-gen init,
+// updated by add-service and the source the Makefile is generated from
+const ProjectConfigFile = "grpc-gen.yaml"
+
+// DefaultDialect is used when init gets no --db and by projects created
+// before grpc-gen.yaml existed
+const DefaultDialect = "mysql"
+
+// Dialects lists the databases a project can be created for
+var Dialects = []string{"mysql", "postgres", "sqlite"}
+
+// DefaultTLSMode is used when init gets no --tls
+const DefaultTLSMode = "mtls"
+
+// TLSModes lists the transport security of a service: mutual TLS, server-only
+// TLS or plaintext (local development only)
+var TLSModes = []string{"mtls", "tls", "insecure"}
+
+// DefaultMigrateMode is the DB_MIGRATE of a service without a migrate option
+const DefaultMigrateMode = "up"
+
+// MigrateModes lists the values of DB_MIGRATE (see database.MigrateOnStartup)
+var MigrateModes = []string{"up", "check", "off"}
+
+// DefaultTraceMode is used when init gets no --trace and by projects created
+// before grpc-gen.yaml had a trace option
+const DefaultTraceMode = "file"
+
+// TraceModes lists the TRACE_MODE of the request tracing interceptor: traces
+// written to log/, printed to stdout, or no interceptor at all
+var TraceModes = []string{"file", "console", "off"}
+
+// DefaultPort is the port of the first service, where --port auto starts
+const DefaultPort = 50051
+
+// serviceNamePattern is the grammar of service names: lower-case words of
+// letters and digits joined by single underscores. The name is the proto
+// package, the Go package and directory, and part of make targets.
+var serviceNamePattern = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z][a-z0-9]*)*$`)
+
+// reservedServiceNames would collide with the project layout (proto/common,
+// proto/grpcgen, src/service/pkg) or are Go keywords
+var reservedServiceNames = map[string]bool{
+	"common": true, "grpcgen": true, "pkg": true,
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+}
+
+// NormalizeServiceName returns the spelling of a service name recorded in
+// grpc-gen.yaml: User becomes user and post-type becomes post_type, so names
+// that would share a directory or proto package are the same service
+func NormalizeServiceName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_")
+}
+
+// ValidateServiceName returns an error if name (normalized) is not a valid
+// service name
+func ValidateServiceName(name string) error {
+	if !serviceNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid service name %q: use lower-case letters and digits, words joined by single underscores, starting with a letter (e.g. user, post_type)", name)
+	}
+	if reservedServiceNames[name] {
+		return fmt.Errorf("service name %q is reserved", name)
+	}
+	return nil
+}
+
+// ProjectConfig is the content of grpc-gen.yaml
+type ProjectConfig struct {
+	// ScaffoldVersion is the grpc-gen version that created or last upgraded
+	// the project (empty before v0.4.0)
+	ScaffoldVersion string `yaml:"scaffold_version,omitempty"`
+
+	Module   string          `yaml:"module"`
+	Dialect  string          `yaml:"dialect"`
+	TLS      string          `yaml:"tls"`
+	Trace    string          `yaml:"trace"`
+	Services []ServiceConfig `yaml:"services"`
+
+	// imported is set when the services were read from an old Makefile
+	imported bool
+}
+
+// ServiceConfig is one service of grpc-gen.yaml. TLS and Migrate are
+// optional per-service overrides.
+type ServiceConfig struct {
+	Name    string `yaml:"name"`
+	Port    int    `yaml:"port"`
+	TLS     string `yaml:"tls,omitempty"`
+	Migrate string `yaml:"migrate,omitempty"`
+}
+
+// GoServiceName is the name of the gRPC service in the proto, e.g. UserService
+// or PostTypeService
+func (s ServiceConfig) GoServiceName() string {
+	return toEntityName(s.Name) + "Service"
+}
+
+// Service returns the service called name, or nil
+func (c *ProjectConfig) Service(name string) *ServiceConfig {
+	for i := range c.Services {
+		if c.Services[i].Name == name {
+			return &c.Services[i]
+		}
+	}
+	return nil
+}
+
+// ServiceOnPort returns the service listening on port, or nil
+func (c *ProjectConfig) ServiceOnPort(port int) *ServiceConfig {
+	for i := range c.Services {
+		if c.Services[i].Port == port {
+			return &c.Services[i]
+		}
+	}
+	return nil
+}
+
+// NextPort returns the lowest port from DefaultPort no service uses
+func (c *ProjectConfig) NextPort() int {
+	port := DefaultPort
+	for c.ServiceOnPort(port) != nil {
+		port++
+	}
+	return port
+}
+
+// ParsePort parses the port of a new service: a number between 1024-65535
+// that no service uses, or "auto" for NextPort
+func (c *ProjectConfig) ParsePort(value string) (int, error) {
+	if value == "auto" {
+		return c.NextPort(), nil
+	}
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1024 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q (must be between 1024-65535, or auto)", value)
+	}
+	return port, c.checkPortFree(port)
+}
+
+// checkPortFree returns an error if a service uses port
+func (c *ProjectConfig) checkPortFree(port int) error {
+	if s := c.ServiceOnPort(port); s != nil {
+		return fmt.Errorf("port %d is already used by service %s (use --port auto for the next free port, %d)", port, s.Name, c.NextPort())
+	}
+	return nil
+}
+
+// TLSMode returns the TLS mode of a service (its override or the project's)
+func (c *ProjectConfig) TLSMode(s ServiceConfig) string {
+	if s.TLS != "" {
+		return s.TLS
+	}
+	return c.TLS
+}
+
+// MigrateMode returns the DB_MIGRATE of a service
+func (c *ProjectConfig) MigrateMode(s ServiceConfig) string {
+	if s.Migrate != "" {
+		return s.Migrate
+	}
+	return DefaultMigrateMode
+}
+
+// ValidateDialect returns an error if name is not a supported database
+func ValidateDialect(name string) error {
+	return validateChoice("database", name, Dialects)
+}
+
+// ValidateTLSMode returns an error if mode is not a supported TLS mode
+func ValidateTLSMode(mode string) error {
+	return validateChoice("TLS mode", mode, TLSModes)
+}
+
+// ValidateTraceMode returns an error if mode is not a supported trace mode
+func ValidateTraceMode(mode string) error {
+	return validateChoice("trace mode", mode, TraceModes)
+}
+
+func validateChoice(what, value string, choices []string) error {
+	for _, c := range choices {
+		if c == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported %s %q (available: %s)", what, value, strings.Join(choices, ", "))
+}
+
+// Validate checks the settings of every service
+func (c *ProjectConfig) Validate() error {
+	if err := ValidateDialect(c.Dialect); err != nil {
+		return err
+	}
+	if err := ValidateTLSMode(c.TLS); err != nil {
+		return err
+	}
+	if err := ValidateTraceMode(c.Trace); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	ports := make(map[int]string)
+	for _, s := range c.Services {
+		if err := ValidateServiceName(s.Name); err != nil {
+			return err
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("service %s is listed twice", s.Name)
+		}
+		seen[s.Name] = true
+
+		if s.Port < 1024 || s.Port > 65535 {
+			return fmt.Errorf("service %s: invalid port %d (must be between 1024-65535)", s.Name, s.Port)
+		}
+		if other, ok := ports[s.Port]; ok {
+			return fmt.Errorf("services %s and %s both use port %d", other, s.Name, s.Port)
+		}
+		ports[s.Port] = s.Name
+		if s.TLS != "" {
+			if err := ValidateTLSMode(s.TLS); err != nil {
+				return fmt.Errorf("service %s: %w", s.Name, err)
+			}
+		}
+		if s.Migrate != "" {
+			if err := validateChoice("migrate mode", s.Migrate, MigrateModes); err != nil {
+				return fmt.Errorf("service %s: %w", s.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadProjectConfig reads grpc-gen.yaml from the current directory. Projects
+// without one get the module from go.mod, the default dialect, TLS and trace
+// modes, and the services found in the Makefile.
+func LoadProjectConfig() (*ProjectConfig, error) {
+	cfg := &ProjectConfig{}
+
+	data, err := os.ReadFile(ProjectConfigFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", ProjectConfigFile, err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", ProjectConfigFile, err)
+		}
+	}
+
+	if cfg.Module == "" {
+		if cfg.Module, err = getModulePath(); err != nil {
+			return nil, fmt.Errorf("not in a project directory (go.mod not found)")
+		}
+	}
+	if cfg.Dialect == "" {
+		cfg.Dialect = DefaultDialect
+	}
+	if cfg.TLS == "" {
+		cfg.TLS = DefaultTLSMode
+	}
+	if cfg.Trace == "" {
+		cfg.Trace = DefaultTraceMode
+	}
+	if cfg.Services == nil {
+		cfg.Services, cfg.imported = makefileServices(), true
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", ProjectConfigFile, err)
+	}
+
+	return cfg, nil
+}
+
+// legacyGenTarget matches the gen targets add-service wrote into the
+// Makefile before services were recorded in grpc-gen.yaml
+var legacyGenTarget = regexp.MustCompile(`(?m)^\t\$\(GEN\) ([a-z0-9_-]+) (\S+) (\d+)$`)
+
+// makefileServices lists the services of a Makefile written before
+// grpc-gen.yaml recorded them
+func makefileServices() []ServiceConfig {
+	data, err := os.ReadFile("Makefile")
+	if err != nil {
+		return nil
+	}
+
+	var services []ServiceConfig
+	for _, m := range legacyGenTarget.FindAllStringSubmatch(string(data), -1) {
+		port, _ := strconv.Atoi(m[3])
+		services = append(services, ServiceConfig{Name: m[1], Port: port})
+	}
+	return services
+}
+
+// SaveProjectConfig writes grpc-gen.yaml to the current directory
+func SaveProjectConfig(cfg *ProjectConfig) error {
+	return saveProjectConfig(cfg, Preview{})
+}
+
+func saveProjectConfig(cfg *ProjectConfig, preview Preview) error {
+	content, err := renderProjectConfig(cfg)
+	if err != nil {
+		return err
+	}
+	_, err = preview.writeFile(ProjectConfigFile, content, 0644)
+	return err
+}
+
+// renderProjectConfig renders grpc-gen.yaml
+func renderProjectConfig(cfg *ProjectConfig) ([]byte, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# grpc-gen project manifest. The Makefile is generated from this file:\n" +
+		"# after editing it, run grpc-gen sync (make does it automatically).\n")
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,356 @@
+package scaffold
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CheckStatus is the outcome of a doctor check
+type CheckStatus int
+
+const (
+	CheckOK CheckStatus = iota
+	CheckWarn
+	CheckFail
+)
+
+// Check is one finding of grpc-gen doctor
+type Check struct {
+	Group  string // Tools, Project or "Service <name>"
+	Name   string
+	Status CheckStatus
+	Detail string
+	Fix    string // what to run or change, for warnings and failures
+}
+
+// Doctor inspects the tools grpc-gen relies on and, inside a project, its
+// manifest, Makefile, go.mod and services
+func Doctor() []Check {
+	d := &doctor{}
+	d.checkTools()
+	if _, err := os.Stat("go.mod"); err != nil {
+		d.add("Project", "go.mod", CheckWarn, "not in a project directory", "run grpc-gen doctor from the project root")
+		return d.checks
+	}
+
+	cfg, err := LoadProjectConfig()
+	if err != nil {
+		d.add("Project", ProjectConfigFile, CheckFail, err.Error(), "fix "+ProjectConfigFile+" by hand, then run grpc-gen sync")
+		return d.checks
+	}
+	d.checkProject(cfg)
+	for _, s := range cfg.Services {
+		d.checkService(cfg, s)
+	}
+	return d.checks
+}
+
+type doctor struct {
+	checks []Check
+	// versions of the protoc plugins, compared with go.mod
+	protocGenGo, protocGenGoGRPC string
+}
+
+func (d *doctor) add(group, name string, status CheckStatus, detail, fix string) {
+	d.checks = append(d.checks, Check{Group: group, Name: name, Status: status, Detail: detail, Fix: fix})
+}
+
+// versionPattern finds the version in the output of a --version flag
+var versionPattern = regexp.MustCompile(`\d+\.\d+(\.\d+)?`)
+
+// toolVersion runs name with args and returns the version it prints
+func toolVersion(name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("%s not found in PATH", name)
+	}
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %v", name, strings.Join(args, " "), err)
+	}
+	version := versionPattern.FindString(string(out))
+	if version == "" {
+		return "", fmt.Errorf("no version in %q", strings.TrimSpace(string(out)))
+	}
+	return "v" + version, nil
+}
+
+func (d *doctor) checkTools() {
+	tools := []struct {
+		name string
+		args []string
+		// min is the oldest version that works, fix how to install it
+		min, fix string
+	}{
+		{"go", []string{"version"}, "v1.24.0", "install Go 1.24+ (https://go.dev/dl)"},
+		// proto3 optional fields need protoc 3.15+ (later releases are numbered 21, 22, ...)
+		{"protoc", []string{"--version"}, "v3.15.0", "install protoc 3.15+ (https://grpc.io/docs/protoc-installation)"},
+		{"protoc-gen-go", []string{"--version"}, "", "go install google.golang.org/protobuf/cmd/protoc-gen-go@latest"},
+		{"protoc-gen-go-grpc", []string{"--version"}, "", "go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@latest"},
+	}
+	for _, tool := range tools {
+		version, err := toolVersion(tool.name, tool.args...)
+		switch {
+		case err != nil:
+			d.add("Tools", tool.name, CheckFail, err.Error(), tool.fix)
+		case tool.min != "" && compareVersions(version, tool.min) < 0:
+			d.add("Tools", tool.name, CheckFail, version+" is older than "+tool.min, tool.fix)
+		default:
+			d.add("Tools", tool.name, CheckOK, version, "")
+		}
+		switch tool.name {
+		case "protoc-gen-go":
+			d.protocGenGo = version
+		case "protoc-gen-go-grpc":
+			d.protocGenGoGRPC = version
+		}
+	}
+
+	// make gen-<service> runs grpc-gen generate
+	if _, err := exec.LookPath("grpc-gen"); err != nil {
+		d.add("Tools", "grpc-gen", CheckWarn, "not in PATH, make gen-<service> will fail",
+			"go install github.com/thailyhcmut/grpc-gen@latest, or add its directory to PATH")
+	} else {
+		d.add("Tools", "grpc-gen", CheckOK, Version, "")
+	}
+}
+
+func (d *doctor) checkProject(cfg *ProjectConfig) {
+	if cfg.imported {
+		d.add("Project", ProjectConfigFile, CheckWarn, "missing, services were read from the Makefile", "grpc-gen upgrade")
+	} else {
+		d.add("Project", ProjectConfigFile, CheckOK, fmt.Sprintf("%d service(s)", len(cfg.Services)), "")
+	}
+
+	switch c := compareVersions(cfg.ScaffoldVersion, Version); {
+	case cfg.ScaffoldVersion == "" || c < 0:
+		d.add("Project", "scaffold version", CheckWarn, fmt.Sprintf("project %s, grpc-gen %s", displayVersion(cfg.ScaffoldVersion), Version), "grpc-gen upgrade")
+	case c > 0:
+		d.add("Project", "scaffold version", CheckFail, fmt.Sprintf("project %s is newer than grpc-gen %s", cfg.ScaffoldVersion, Version), "install a newer grpc-gen")
+	default:
+		d.add("Project", "scaffold version", CheckOK, Version, "")
+	}
+
+	d.checkModule(cfg)
+	d.checkGoMod(cfg)
+	d.checkMakefile(cfg)
+}
+
+// minorVersion drops the patch of a version: v1.36.10 becomes v1.36.0
+func minorVersion(v string) string {
+	p := parseVersion(v)
+	return fmt.Sprintf("v%d.%d.0", p[0], p[1])
+}
+
+func displayVersion(v string) string {
+	if v == "" {
+		return "unrecorded (before v0.4.0)"
+	}
+	return v
+}
+
+// goPackagePattern matches the go_package option of a proto file
+var goPackagePattern = regexp.MustCompile(`option\s+go_package\s*=\s*"([^";]+)`)
+
+// checkModule compares the module of grpc-gen.yaml, go.mod and the
+// go_package of the protos
+func (d *doctor) checkModule(cfg *ProjectConfig) {
+	goMod, err := getModulePath()
+	if err != nil {
+		d.add("Project", "module", CheckFail, "go.mod: "+err.Error(), "add a module line to go.mod")
+		return
+	}
+	if goMod != cfg.Module {
+		d.add("Project", "module", CheckFail, fmt.Sprintf("%s has %s, go.mod has %s", ProjectConfigFile, cfg.Module, goMod),
+			"set module in "+ProjectConfigFile+" to the module of go.mod")
+		return
+	}
+
+	protos := []string{filepath.Join("proto", "common", "common.proto")}
+	for _, s := range cfg.Services {
+		protos = append(protos, filepath.Join("proto", s.Name, s.Name+".proto"))
+	}
+	for _, path := range protos {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue // reported with the service
+		}
+		m := goPackagePattern.FindSubmatch(data)
+		if m == nil {
+			d.add("Project", "module", CheckFail, path+" has no go_package option",
+				fmt.Sprintf(`add option go_package = "%s/%s";`, goMod, filepath.ToSlash(filepath.Dir(path))))
+			return
+		}
+		if pkg := strings.SplitN(string(m[1]), ";", 2)[0]; !strings.HasPrefix(pkg, goMod+"/") {
+			d.add("Project", "module", CheckFail, fmt.Sprintf("%s: go_package %s is outside module %s", path, pkg, goMod),
+				fmt.Sprintf(`set option go_package = "%s/%s";`, goMod, filepath.ToSlash(filepath.Dir(path))))
+			return
+		}
+	}
+	d.add("Project", "module", CheckOK, goMod, "")
+}
+
+// requirePattern matches a requirement of go.mod, in or out of a block
+var requirePattern = regexp.MustCompile(`(?m)^(?:require\s+|\t)(\S+)\s+(v\S+)`)
+
+// grpcForPlugin is the oldest grpc runtime for code generated by
+// protoc-gen-go-grpc 1.4+ (grpc.SupportPackageIsVersion9)
+const grpcForPlugin = "v1.64.0"
+
+// checkGoMod checks the requirements of the generated code and that the
+// runtime is not older than the protoc plugins
+func (d *doctor) checkGoMod(cfg *ProjectConfig) {
+	data, err := os.ReadFile("go.mod")
+	if err != nil {
+		d.add("Project", "go.mod", CheckFail, err.Error(), "")
+		return
+	}
+	requires := make(map[string]string)
+	for _, m := range requirePattern.FindAllStringSubmatch(string(data), -1) {
+		requires[m[1]] = m[2]
+	}
+
+	needed := []string{
+		"github.com/google/uuid",
+		"github.com/joho/godotenv",
+		"google.golang.org/grpc",
+		"google.golang.org/protobuf",
+		"modernc.org/sqlite",
+	}
+	if driver, ok := dialectDrivers[cfg.Dialect]; ok {
+		needed = append(needed, strings.Fields(driver)[0])
+	}
+	var missing []string
+	for _, module := range needed {
+		if requires[module] == "" {
+			missing = append(missing, module)
+		}
+	}
+	if len(missing) > 0 {
+		d.add("Project", "go.mod", CheckFail, "missing "+strings.Join(missing, ", "), "go get "+strings.Join(missing, " ")+" && go mod tidy")
+		return
+	}
+
+	// Generated code needs a runtime of at least the minor version of its
+	// generator
+	if v := requires["google.golang.org/protobuf"]; d.protocGenGo != "" && compareVersions(v, minorVersion(d.protocGenGo)) < 0 {
+		d.add("Project", "go.mod", CheckFail, fmt.Sprintf("google.golang.org/protobuf %s is older than protoc-gen-go %s", v, d.protocGenGo),
+			"go get google.golang.org/protobuf@"+d.protocGenGo+", or install the protoc-gen-go of go.mod: go install google.golang.org/protobuf/cmd/protoc-gen-go@"+v)
+		return
+	}
+	if v := requires["google.golang.org/grpc"]; d.protocGenGoGRPC != "" && compareVersions(d.protocGenGoGRPC, "v1.4.0") >= 0 && compareVersions(v, grpcForPlugin) < 0 {
+		d.add("Project", "go.mod", CheckFail, fmt.Sprintf("google.golang.org/grpc %s is older than %s, needed by protoc-gen-go-grpc %s", v, grpcForPlugin, d.protocGenGoGRPC),
+			"go get google.golang.org/grpc@"+grpcForPlugin)
+		return
+	}
+	d.add("Project", "go.mod", CheckOK, "requirements present", "")
+}
+
+// checkMakefile checks that the Makefile is the one generated from
+// grpc-gen.yaml: add-service and make rely on its targets
+func (d *doctor) checkMakefile(cfg *ProjectConfig) {
+	want, err := renderMakefile(cfg)
+	if err != nil {
+		d.add("Project", "Makefile", CheckFail, err.Error(), "")
+		return
+	}
+	got, err := os.ReadFile("Makefile")
+	switch {
+	case err != nil:
+		d.add("Project", "Makefile", CheckFail, "missing", "grpc-gen sync")
+	case !bytes.HasPrefix(got, []byte(makefileHeader)):
+		d.add("Project", "Makefile", CheckWarn, "not generated from "+ProjectConfigFile+" (written by an older grpc-gen)",
+			"grpc-gen sync (the old Makefile is kept as Makefile.orig)")
+	case !bytes.Equal(got, want):
+		d.add("Project", "Makefile", CheckWarn, "edited or out of date with "+ProjectConfigFile,
+			"grpc-gen sync, and keep your own targets in local.mk")
+	default:
+		d.add("Project", "Makefile", CheckOK, "matches "+ProjectConfigFile, "")
+	}
+}
+
+func (d *doctor) checkService(cfg *ProjectConfig, s ServiceConfig) {
+	group := "Service " + s.Name
+	dir := filepath.Join("src", "service", s.Name)
+
+	proto := filepath.Join("proto", s.Name, s.Name+".proto")
+	if _, err := os.Stat(proto); err != nil {
+		d.add(group, "proto", CheckFail, proto+" is missing", "restore it, or grpc-gen remove-service "+s.Name)
+		return
+	}
+	if _, err := os.Stat(filepath.Join("proto", s.Name, s.Name+".pb.go")); err != nil {
+		d.add(group, "proto", CheckWarn, "protoc has not been run", "make proto-"+s.Name)
+	} else {
+		d.add(group, "proto", CheckOK, proto, "")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "main_gen.go")); err != nil {
+		d.add(group, "code", CheckWarn, "not generated", "make gen-"+s.Name)
+		return
+	}
+	d.add(group, "code", CheckOK, dir, "")
+
+	var missing []string
+	for _, env := range []string{s.Name + ".env", s.Name + "_gen.env"} {
+		if _, err := os.Stat(filepath.Join(dir, env)); err != nil {
+			missing = append(missing, env)
+		}
+	}
+	if len(missing) > 0 {
+		d.add(group, "env", CheckFail, "missing "+strings.Join(missing, ", "), "make gen-"+s.Name+" (creates "+s.Name+".env once, fill in the credentials)")
+	} else {
+		d.add(group, "env", CheckOK, s.Name+".env, "+s.Name+"_gen.env", "")
+	}
+
+	d.checkCerts(group, cfg.TLSMode(s), s.Name, filepath.Join(dir, "certs"))
+}
+
+// checkCerts checks the certificates LoadServerCredentials reads in
+// development for mode
+func (d *doctor) checkCerts(group, mode, service, dir string) {
+	if mode == "insecure" {
+		d.add(group, "certs", CheckOK, "not needed (tls: insecure)", "")
+		return
+	}
+
+	files := []string{service + "-server.crt", service + "-server.key"}
+	if mode == "mtls" {
+		files = append(files, "ca.crt")
+	}
+	fix := "./generate-certs.sh " + service + " (or set tls: insecure for local development)"
+	var missing []string
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) > 0 {
+		d.add(group, "certs", CheckFail, fmt.Sprintf("%s: missing %s (TLS %s)", dir, strings.Join(missing, ", "), mode), fix)
+		return
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, service+"-server.crt"))
+	block, _ := pem.Decode(data)
+	if block == nil {
+		d.add(group, "certs", CheckFail, service+"-server.crt is not a PEM certificate", fix)
+		return
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	switch {
+	case err != nil:
+		d.add(group, "certs", CheckFail, service+"-server.crt: "+err.Error(), fix)
+	case time.Now().After(cert.NotAfter):
+		d.add(group, "certs", CheckFail, fmt.Sprintf("%s-server.crt expired on %s", service, cert.NotAfter.Format("2006-01-02")), fix)
+	case time.Until(cert.NotAfter) < 30*24*time.Hour:
+		d.add(group, "certs", CheckWarn, fmt.Sprintf("%s-server.crt expires on %s", service, cert.NotAfter.Format("2006-01-02")), fix)
+	default:
+		d.add(group, "certs", CheckOK, fmt.Sprintf("TLS %s, valid until %s", mode, cert.NotAfter.Format("2006-01-02")), "")
+	}
+}
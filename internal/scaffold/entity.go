@@ -0,0 +1,417 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/parser"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/types"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/utils"
+)
+
+// EntityField is a field given to add-entity as name:type,
+// name:optional:type or name:enum(A,B)
+type EntityField struct {
+	Name       string // snake_case proto field name
+	Type       string // proto scalar type or "timestamp"; empty for enums
+	Optional   bool
+	EnumValues []string
+}
+
+// entityFieldTypes are the field types add-entity accepts besides enum(...)
+var entityFieldTypes = map[string]bool{
+	"string": true, "bytes": true, "bool": true,
+	"int32": true, "int64": true, "uint32": true, "uint64": true,
+	"float": true, "double": true, "timestamp": true,
+}
+
+// auditFields are added to every entity by add-entity (and expected by the
+// CRUD handler), so they cannot be given as --field
+var auditFields = map[string]bool{
+	"id": true, "created_at": true, "updated_at": true, "created_by": true, "updated_by": true,
+}
+
+var (
+	entityNameRegex = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	fieldNameRegex  = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	enumValueRegex  = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+	enumTypeRegex   = regexp.MustCompile(`^enum\((.*)\)$`)
+)
+
+// ParseEntityField parses one --field of add-entity
+func ParseEntityField(spec string) (EntityField, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 || (len(parts) == 3 && parts[1] != "optional") {
+		return EntityField{}, fmt.Errorf("invalid field %q (expected name:type, name:optional:type or name:enum(A,B))", spec)
+	}
+
+	field := EntityField{Name: parts[0], Optional: len(parts) == 3}
+	if !fieldNameRegex.MatchString(field.Name) {
+		return field, fmt.Errorf("invalid field name %q (expected snake_case)", field.Name)
+	}
+	if auditFields[field.Name] {
+		return field, fmt.Errorf("field %s is added to every entity, leave it out", field.Name)
+	}
+
+	typ := parts[len(parts)-1]
+	if m := enumTypeRegex.FindStringSubmatch(typ); m != nil {
+		seen := make(map[string]bool)
+		for _, value := range strings.Split(m[1], ",") {
+			value = strings.TrimSpace(value)
+			if !enumValueRegex.MatchString(value) {
+				return field, fmt.Errorf("field %s: invalid enum value %q (expected UPPER_CASE)", field.Name, value)
+			}
+			if seen[value] {
+				return field, fmt.Errorf("field %s: enum value %s is listed twice", field.Name, value)
+			}
+			seen[value] = true
+			field.EnumValues = append(field.EnumValues, value)
+		}
+		return field, nil
+	}
+
+	if !entityFieldTypes[typ] {
+		return field, fmt.Errorf("field %s: unsupported type %q (string, bytes, bool, int32, int64, uint32, uint64, float, double, timestamp or enum(A,B))", field.Name, typ)
+	}
+	field.Type = typ
+	return field, nil
+}
+
+// protoType returns the proto type of a field of entity
+func (f EntityField) protoType(entity string) string {
+	switch {
+	case f.EnumValues != nil:
+		return f.enumName(entity)
+	case f.Type == "timestamp":
+		return "google.protobuf.Timestamp"
+	}
+	return f.Type
+}
+
+// enumName names the enum of an enum field, e.g. Enrollment + status -> EnrollmentStatus
+func (f EntityField) enumName(entity string) string {
+	return entity + toEntityName(f.Name)
+}
+
+// enumValues returns the proto names of the values of an enum field,
+// prefixed with the enum name (ACTIVE -> ENROLLMENT_STATUS_ACTIVE) since
+// proto3 enum values share the package scope. The handler stores them
+// without the prefix.
+func (f EntityField) enumValues(entity string) []string {
+	prefix := strings.ToUpper(toSnakeCase(f.enumName(entity))) + "_"
+	values := make([]string, len(f.EnumValues))
+	for i, v := range f.EnumValues {
+		if strings.HasPrefix(v, prefix) {
+			values[i] = v
+		} else {
+			values[i] = prefix + v
+		}
+	}
+	return values
+}
+
+// Entity is a CRUD entity to add to a service proto
+type Entity struct {
+	Name   string // PascalCase, e.g. Enrollment
+	Fields []EntityField
+}
+
+// ParseEntity parses an entity given as one string: its name followed by
+// its fields, separated by spaces, e.g. "Enrollment name:string score:optional:int32"
+func ParseEntity(spec string) (Entity, error) {
+	words := strings.Fields(spec)
+	if len(words) == 0 {
+		return Entity{}, fmt.Errorf("empty entity")
+	}
+
+	entity := Entity{Name: words[0]}
+	for _, word := range words[1:] {
+		field, err := ParseEntityField(word)
+		if err != nil {
+			return entity, fmt.Errorf("entity %s: %w", entity.Name, err)
+		}
+		entity.Fields = append(entity.Fields, field)
+	}
+	return entity, entity.validate()
+}
+
+// validate checks the entity name and that its fields are listed once
+func (e Entity) validate() error {
+	if !entityNameRegex.MatchString(e.Name) {
+		return fmt.Errorf("invalid entity name %q (expected PascalCase, e.g. Enrollment)", e.Name)
+	}
+	if len(e.Fields) == 0 {
+		return fmt.Errorf("entity %s needs at least one field", e.Name)
+	}
+	seen := make(map[string]bool)
+	for _, f := range e.Fields {
+		if seen[f.Name] {
+			return fmt.Errorf("entity %s: field %s is listed twice", e.Name, f.Name)
+		}
+		seen[f.Name] = true
+	}
+	return nil
+}
+
+// ValidateEntities checks entities the way add-service declares them in the
+// starter proto of service, so a wizard can refuse them before writing files
+func ValidateEntities(service string, entities []Entity) error {
+	service = NormalizeServiceName(service)
+	_, err := createServiceProto(service, toEntityName(service), entities)
+	return err
+}
+
+// AddEntity appends a CRUD entity (enums, entity message, request/response
+// messages and rpcs) to the proto of an existing service
+func AddEntity(service string, entity Entity, preview Preview) error {
+	service = NormalizeServiceName(service)
+	if err := entity.validate(); err != nil {
+		return err
+	}
+
+	protoFile := filepath.Join("proto", service, service+".proto")
+	src, err := os.ReadFile(protoFile)
+	if err != nil {
+		return fmt.Errorf("service %s not found (%s is missing)", service, protoFile)
+	}
+
+	content, err := addEntityToProto(protoFile, service, string(src), entity)
+	if err != nil {
+		return err
+	}
+	if _, err := preview.writeFile(protoFile, []byte(content), 0644); err != nil {
+		return err
+	}
+	if !preview.Enabled() {
+		fmt.Printf("  ✓ Added %s (%d fields, 5 rpcs) to %s\n", entity.Name, len(entity.Fields), protoFile)
+	}
+	return nil
+}
+
+// addEntityToProto returns src, the proto of service, with entity added
+func addEntityToProto(protoFile, service, src string, entity Entity) (string, error) {
+	file, err := parser.ParseProto(protoFile, []byte(src))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", protoFile, err)
+	}
+
+	svc := findService(file, service)
+	if svc == nil {
+		return "", fmt.Errorf("%s has no service to add the rpcs to", protoFile)
+	}
+	if err := checkEntityConflicts(file, svc, entity.Name, entity.Fields); err != nil {
+		return "", err
+	}
+	return insertEntity(src, file, svc, entity.Name, entity.Fields), nil
+}
+
+// findService returns the service of a proto: the only one, or <Name>Service
+func findService(file *types.ProtoFile, service string) *types.ProtoService {
+	if len(file.Services) == 1 {
+		return file.Services[0]
+	}
+	name := ServiceConfig{Name: service}.GoServiceName()
+	for _, svc := range file.Services {
+		if svc.Name == name {
+			return svc
+		}
+	}
+	return nil
+}
+
+// checkEntityConflicts refuses names already declared in the proto. Enum
+// values share the package scope in proto3, so they must be unique across
+// every top-level enum.
+func checkEntityConflicts(file *types.ProtoFile, svc *types.ProtoService, entity string, fields []EntityField) error {
+	declared := make(map[string]bool)
+	for _, m := range file.Messages {
+		declared[m.Name] = true
+	}
+	enumValues := make(map[string]string)
+	for _, e := range file.Enums {
+		declared[e.Name] = true
+		for _, v := range e.Values {
+			enumValues[v.Name] = e.Name
+		}
+	}
+	for _, rpc := range svc.RPCs {
+		declared[rpc.Name] = true
+	}
+
+	names := []string{entity}
+	for _, kind := range []string{"Create", "Get", "Update", "Delete"} {
+		names = append(names, kind+entity, kind+entity+"Request", kind+entity+"Response")
+	}
+	plural := utils.Pluralize(entity)
+	names = append(names, "List"+plural, "List"+plural+"Request", "List"+plural+"Response")
+
+	for _, f := range fields {
+		if f.EnumValues == nil {
+			continue
+		}
+		names = append(names, f.enumName(entity))
+		for _, v := range f.enumValues(entity) {
+			if owner, ok := enumValues[v]; ok {
+				return fmt.Errorf("enum value %s is already declared in %s (enum values share the package scope, pick another name)", v, owner)
+			}
+			enumValues[v] = f.enumName(entity)
+		}
+	}
+
+	for _, name := range names {
+		if declared[name] {
+			return fmt.Errorf("%s is already declared in the proto", name)
+		}
+	}
+	return nil
+}
+
+// insertEntity adds the messages of an entity above the service (and its
+// leading comments), its rpcs before the closing brace of the service and
+// the imports they need
+func insertEntity(src string, file *types.ProtoFile, svc *types.ProtoService, entity string, fields []EntityField) string {
+	lines := strings.Split(src, "\n")
+
+	messagesAt := svc.Pos.Line - 1
+	for messagesAt > 0 && strings.HasPrefix(strings.TrimSpace(lines[messagesAt-1]), "//") {
+		messagesAt--
+	}
+	rpcsAt := svc.EndPos.Line - 1
+
+	var out []string
+	out = append(out, lines[:messagesAt]...)
+	out = append(out, strings.Split(strings.TrimSuffix(renderEntityMessages(entity, fields), "\n"), "\n")...)
+	out = append(out, lines[messagesAt:rpcsAt]...)
+	if !strings.HasSuffix(strings.TrimSpace(lines[rpcsAt-1]), "{") {
+		out = append(out, "")
+	}
+	out = append(out, strings.Split(renderEntityRPCs(entity), "\n")...)
+	out = append(out, lines[rpcsAt:]...)
+
+	return strings.Join(insertImports(out, file,
+		"google/protobuf/timestamp.proto",
+		"proto/common/common.proto",
+	), "\n")
+}
+
+// insertImports adds the imports file is missing after its last import, or
+// after the package statement when it has none. lines must be unchanged
+// above the insertion point.
+func insertImports(lines []string, file *types.ProtoFile, paths ...string) []string {
+	imported := make(map[string]bool)
+	for _, imp := range file.Imports {
+		imported[imp.Path] = true
+	}
+	var missing []string
+	for _, path := range paths {
+		if !imported[path] {
+			missing = append(missing, fmt.Sprintf("import %q;", path))
+		}
+	}
+	if len(missing) == 0 {
+		return lines
+	}
+
+	at := 0
+	if len(file.Imports) > 0 {
+		at = file.Imports[len(file.Imports)-1].Pos.Line
+	} else {
+		for i, line := range lines {
+			if strings.HasPrefix(strings.TrimSpace(line), "package ") {
+				at = i + 1
+				missing = append([]string{""}, missing...)
+				break
+			}
+		}
+	}
+
+	out := append([]string{}, lines[:at]...)
+	out = append(out, missing...)
+	return append(out, lines[at:]...)
+}
+
+// renderEntityMessages renders the enums, entity and request/response
+// messages of an entity, numbering each message's fields from 1
+func renderEntityMessages(entity string, fields []EntityField) string {
+	var b strings.Builder
+	snake := toSnakeCase(entity)
+	plural := utils.Pluralize(entity)
+
+	fmt.Fprintf(&b, "// ============= %s =============\n", entity)
+	for _, f := range fields {
+		if f.EnumValues == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "enum %s {\n", f.enumName(entity))
+		for i, v := range f.enumValues(entity) {
+			fmt.Fprintf(&b, "  %s = %d;\n", v, i)
+		}
+		b.WriteString("}\n\n")
+	}
+
+	message := func(name string, lines ...string) {
+		fmt.Fprintf(&b, "message %s {\n", name)
+		for i, line := range lines {
+			fmt.Fprintf(&b, "  %s = %d;\n", line, i+1)
+		}
+		b.WriteString("}\n\n")
+	}
+	field := func(f EntityField, optional bool) string {
+		if optional {
+			return fmt.Sprintf("optional %s %s", f.protoType(entity), f.Name)
+		}
+		return fmt.Sprintf("%s %s", f.protoType(entity), f.Name)
+	}
+
+	entityLines := []string{"string id"}
+	createLines := []string{}
+	updateLines := []string{"string id"}
+	for _, f := range fields {
+		entityLines = append(entityLines, field(f, f.Optional))
+		createLines = append(createLines, field(f, f.Optional))
+		updateLines = append(updateLines, field(f, true))
+	}
+	entityLines = append(entityLines,
+		"google.protobuf.Timestamp created_at",
+		"google.protobuf.Timestamp updated_at",
+		"string created_by",
+		"string updated_by",
+	)
+	createLines = append(createLines, "string created_by")
+	updateLines = append(updateLines, "string updated_by")
+
+	message(entity, entityLines...)
+	message("Create"+entity+"Request", createLines...)
+	message("Create"+entity+"Response", entity+" "+snake)
+	message("Get"+entity+"Request", "string id")
+	message("Get"+entity+"Response", entity+" "+snake)
+	message("Update"+entity+"Request", updateLines...)
+	message("Update"+entity+"Response", entity+" "+snake)
+	message("Delete"+entity+"Request", "string id")
+	message("Delete"+entity+"Response", "bool success")
+	message("List"+plural+"Request", "common.SearchRequest search")
+	message("List"+plural+"Response",
+		"repeated "+entity+" "+toSnakeCase(plural),
+		"int32 total",
+		"int32 page",
+		"int32 page_size",
+		"string next_page_token",
+	)
+
+	return b.String()
+}
+
+// renderEntityRPCs renders the five CRUD rpc declarations of an entity
+func renderEntityRPCs(entity string) string {
+	plural := utils.Pluralize(entity)
+	var b strings.Builder
+	fmt.Fprintf(&b, "  // %s\n", entity)
+	for _, kind := range []string{"Create", "Get", "Update", "Delete"} {
+		fmt.Fprintf(&b, "  rpc %[1]s%[2]s(%[1]s%[2]sRequest) returns (%[1]s%[2]sResponse);\n", kind, entity)
+	}
+	fmt.Fprintf(&b, "  rpc List%[1]s(List%[1]sRequest) returns (List%[1]sResponse);", plural)
+	return b.String()
+}
@@ -0,0 +1,229 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thailyhcmut/grpc-gen/internal/migration"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/generator"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/parser"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/types"
+)
+
+const userProto = `syntax = "proto3";
+
+package user;
+
+option go_package = "example.com/demo/proto/user";
+
+import "google/protobuf/timestamp.proto";
+
+enum UserStatus {
+  ACTIVE = 0;
+  INACTIVE = 1;
+}
+
+message User {
+  string id = 1;
+  UserStatus status = 2;
+}
+
+// ============= Service =============
+service UserService {
+  rpc GetUser(User) returns (User);
+}
+`
+
+func TestParseEntityField(t *testing.T) {
+	tests := []struct {
+		spec string
+		want EntityField
+	}{
+		{"name:string", EntityField{Name: "name", Type: "string"}},
+		{"score:optional:int32", EntityField{Name: "score", Type: "int32", Optional: true}},
+		{"deadline:timestamp", EntityField{Name: "deadline", Type: "timestamp"}},
+		{"status:enum(ACTIVE, INACTIVE)", EntityField{Name: "status", EnumValues: []string{"ACTIVE", "INACTIVE"}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseEntityField(tt.spec)
+		if err != nil {
+			t.Errorf("ParseEntityField(%q): unexpected error: %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseEntityField(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+
+	invalid := map[string]string{
+		"name":                       "expected name:type",
+		"name:required:string":       "expected name:type",
+		"Name:string":                "invalid field name",
+		"created_at:timestamp":       "added to every entity",
+		"name:text":                  "unsupported type",
+		"status:enum(active)":        "invalid enum value",
+		"status:enum(ACTIVE,ACTIVE)": "listed twice",
+	}
+	for spec, want := range invalid {
+		if _, err := ParseEntityField(spec); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseEntityField(%q) error = %v, want it to contain %q", spec, err, want)
+		}
+	}
+}
+
+func TestAddEntityToProtoNumbering(t *testing.T) {
+	entity, err := ParseEntity("Enrollment name:string status:enum(ENROLLED,DROPPED) score:optional:int32")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, err := addEntityToProto("proto/user/user.proto", "user", userProto, entity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	file, err := parser.ParseProto("proto/user/user.proto", []byte(content))
+	if err != nil {
+		t.Fatalf("generated proto does not parse: %v\n%s", err, content)
+	}
+
+	numbers := func(name string) string {
+		for _, m := range file.Messages {
+			if m.Name == name {
+				var fields []string
+				for _, f := range m.Fields {
+					fields = append(fields, fmt.Sprintf("%s=%d", f.Name, f.Number))
+				}
+				return strings.Join(fields, " ")
+			}
+		}
+		return "missing"
+	}
+	want := map[string]string{
+		"Enrollment":               "id=1 name=2 status=3 score=4 created_at=5 updated_at=6 created_by=7 updated_by=8",
+		"CreateEnrollmentRequest":  "name=1 status=2 score=3 created_by=4",
+		"UpdateEnrollmentRequest":  "id=1 name=2 status=3 score=4 updated_by=5",
+		"ListEnrollmentsResponse":  "enrollments=1 total=2 page=3 page_size=4 next_page_token=5",
+		"DeleteEnrollmentResponse": "success=1",
+		"ListEnrollmentsRequest":   "search=1",
+		"GetEnrollmentRequest":     "id=1",
+		"CreateEnrollmentResponse": "enrollment=1",
+		"User":                     "id=1 status=2",
+	}
+	for name, fields := range want {
+		if got := numbers(name); got != fields {
+			t.Errorf("%s fields = %s, want %s", name, got, fields)
+		}
+	}
+
+	var enum *types.ProtoEnum
+	for _, e := range file.Enums {
+		if e.Name == "EnrollmentStatus" {
+			enum = e
+		}
+	}
+	if enum == nil {
+		t.Fatalf("EnrollmentStatus enum missing:\n%s", content)
+	}
+	var values []string
+	for _, v := range enum.Values {
+		values = append(values, fmt.Sprintf("%s=%d", v.Name, v.Number))
+	}
+	if got := strings.Join(values, " "); got != "ENROLLMENT_STATUS_ENROLLED=0 ENROLLMENT_STATUS_DROPPED=1" {
+		t.Errorf("EnrollmentStatus values = %s", got)
+	}
+}
+
+func TestAddEntityToProtoEnumValues(t *testing.T) {
+	// UserStatus already declares ACTIVE; the prefix keeps the new values apart
+	entity, err := ParseEntity("Enrollment status:enum(ACTIVE,INACTIVE)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, err := addEntityToProto("proto/user/user.proto", "user", userProto, entity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(content, "  ENROLLMENT_STATUS_ACTIVE = 0;\n  ENROLLMENT_STATUS_INACTIVE = 1;\n") {
+		t.Errorf("prefixed enum values missing:\n%s", content)
+	}
+
+	// Adding it again collides with the enum declared above
+	again, _ := ParseEntity("Enrollment status:enum(ACTIVE)")
+	if _, err := addEntityToProto("proto/user/user.proto", "user", content, again); err == nil || !strings.Contains(err.Error(), "already declared") {
+		t.Errorf("error = %v, want already declared", err)
+	}
+
+	// Values already carrying the prefix are kept as they are
+	prefixed, _ := ParseEntity("Course status:enum(COURSE_STATUS_OPEN)")
+	content, err = addEntityToProto("proto/user/user.proto", "user", userProto, prefixed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(content, "  COURSE_STATUS_OPEN = 0;\n") {
+		t.Errorf("COURSE_STATUS_OPEN missing:\n%s", content)
+	}
+}
+
+func TestAddEntityPlurals(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("go.mod", []byte("module example.com/demo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Join("proto", "common"), 0755)
+	os.MkdirAll(filepath.Join("proto", "school"), 0755)
+	if err := os.WriteFile(filepath.Join("proto", "common", "common.proto"), commonProto("example.com/demo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Course ends in "se" and Class in "s": neither plural singularizes back
+	// by dropping a suffix, so the List rpcs must still reach their entity
+	var entities []Entity
+	for _, spec := range []string{"Course title:string", "Class room:string", "Faculty name:string"} {
+		entity, err := ParseEntity(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entities = append(entities, entity)
+	}
+	content, err := createServiceProto("school", "School", entities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rpc := range []string{"ListCourses(ListCoursesRequest)", "ListClasses(ListClassesRequest)", "ListFaculties(ListFacultiesRequest)"} {
+		if !strings.Contains(content, "rpc "+rpc) {
+			t.Errorf("rpc %s missing:\n%s", rpc, content)
+		}
+	}
+	if err := os.WriteFile(filepath.Join("proto", "school", "school.proto"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	generator.Templates = templateFS()
+	generator.GenerateService(types.Data{ProtoName: "school", ServiceName: "SchoolService", Port: "50051", Dialect: "sqlite"})
+
+	for file, field := range map[string]string{"course_gen.go": "Courses", "class_gen.go": "Classes", "faculty_gen.go": "Faculties"} {
+		data, err := os.ReadFile(filepath.Join("src", "service", "school", "handler", file))
+		if err != nil {
+			t.Errorf("%s not generated: %v", file, err)
+			continue
+		}
+		if !strings.Contains(string(data), field+": entities,") {
+			t.Errorf("%s does not fill the %s list field", file, field)
+		}
+	}
+
+	tables, err := migration.LoadTables("school", "example.com/demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, table := range tables {
+		names = append(names, table.Name)
+	}
+	if got := strings.Join(names, " "); got != "Class Course Faculty" {
+		t.Errorf("tables = %s, want Class Course Faculty", got)
+	}
+}
@@ -0,0 +1,63 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/generator"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/types"
+)
+
+// GenerateServices generates the skeleton of the named services (every
+// service of grpc-gen.yaml when names is empty) with the generator built into
+// this binary. Templates come from the project's template/ directory when it
+// has them, from the binary otherwise. force overwrites files without the
+// generated header.
+func GenerateServices(names []string, force bool, preview Preview) error {
+	cfg, err := LoadProjectConfig()
+	if err != nil {
+		return err
+	}
+
+	var services []ServiceConfig
+	if len(names) == 0 {
+		services = cfg.Services
+	}
+	for _, name := range names {
+		s := cfg.Service(name)
+		if s == nil {
+			return fmt.Errorf("service %s is not in %s", name, ProjectConfigFile)
+		}
+		services = append(services, *s)
+	}
+	if len(services) == 0 {
+		return fmt.Errorf("no services in %s (add one with grpc-gen add-service)", ProjectConfigFile)
+	}
+
+	for _, s := range services {
+		protoFile := filepath.Join("proto", s.Name, s.Name+".proto")
+		if _, err := os.Stat(protoFile); err != nil {
+			return fmt.Errorf("service %s: %s is missing", s.Name, protoFile)
+		}
+	}
+
+	generator.Force = force
+	generator.DryRun = preview.DryRun
+	generator.Diff = preview.Diff
+	generator.Templates = templateFS()
+
+	for _, s := range services {
+		generator.GenerateService(types.Data{
+			ProtoName:   s.Name,
+			ServiceName: s.GoServiceName(),
+			Port:        strconv.Itoa(s.Port),
+			Dialect:     cfg.Dialect,
+			TLSMode:     cfg.TLSMode(s),
+			Migrate:     cfg.MigrateMode(s),
+			Trace:       cfg.Trace,
+		})
+	}
+	return nil
+}
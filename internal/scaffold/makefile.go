@@ -1,44 +1,112 @@
 package scaffold
 
 import (
-	
+	"bytes"
+	"fmt"
 	"os"
+	"strings"
+	"text/template"
 )
 
-func createMakefile(modulePath string) error {
-	content := `PROTOC = protoc --go_out=. --go_opt=paths=source_relative \
-               --go-grpc_out=. --go-grpc_opt=paths=source_relative
-GEN_BIN = ./gen_skeleton
-GEN = $(GEN_BIN)
+// makefileHeader marks the Makefile as generated from grpc-gen.yaml
+const makefileHeader = "# Code generated by grpc-gen from grpc-gen.yaml. DO NOT EDIT."
+
+// makefileTemplate renders the Makefile from a ProjectConfig
+var makefileTemplate = template.Must(template.New("Makefile").Parse(makefileHeader + `
+# Add services with grpc-gen add-service or edit grpc-gen.yaml; put your own
+# targets in local.mk.
 
-# Build code generator binary
-gen-tool:
-	cd scripts && go build -o ../$(GEN_BIN) gen_skeleton.go
+PROTOC = protoc --go_out=. --go_opt=paths=source_relative \
+               --go-grpc_out=. --go-grpc_opt=paths=source_relative
+# GEN_FLAGS=--force overwrites files without the "Code generated" header
+GEN = grpc-gen generate $(GEN_FLAGS)
 
-# Service port mappings
-# Add your services here with their ports
+.DEFAULT_GOAL := all
 
 # Proto generation
 proto-common:
 	$(PROTOC) proto/common/common.proto
 
+proto-grpcgen:
+	$(PROTOC) proto/grpcgen/options.proto
+{{range .Services}}
+proto-{{.Name}}:
+	$(PROTOC) proto/{{.Name}}/{{.Name}}.proto
+{{end}}
 # Generate all protos
-all: proto-common
+all: proto-common proto-grpcgen{{range .Services}} proto-{{.Name}}{{end}}
+
+# Regenerate this Makefile when grpc-gen.yaml changes
+Makefile: grpc-gen.yaml
+	grpc-gen sync
+
+# Service skeletons (port, TLS, migrate and trace mode from grpc-gen.yaml)
+{{- range .Services}}
+
+gen-{{.Name}}: proto-{{.Name}}
+	$(GEN) {{.Name}}
+
+migrate-{{.Name}}:
+	grpc-gen gen-migration {{.Name}}
+{{- end}}
 
 # Generate all service skeletons
-gen-all: gen-tool all
+gen-all: all{{range .Services}} gen-{{.Name}}{{end}}
 
-# Clean generated files
+# Clean generated files (hooks.go, <entity>.go and <service>.env are yours)
 clean:
-	rm -rf src/service/*
-	rm -f gen_skeleton
+	find src/service -type f \( -name '*_gen.go' -o -name '*_gen_test.go' -o -name '*_gen.env' \) -delete
 
 # Clean everything including proto generated files
 clean-all: clean
 	find proto -name "*.pb.go" -delete
 
-.PHONY: gen-tool proto-common all gen-all clean clean-all
-`
+-include local.mk
+
+.PHONY: proto-common proto-grpcgen all gen-all clean clean-all
+`))
+
+// renderMakefile renders the Makefile of a project
+func renderMakefile(cfg *ProjectConfig) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := makefileTemplate.Execute(&buf, cfg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeMakefile regenerates the Makefile from cfg. A Makefile written before
+// it was generated (string-patched by add-service, maybe edited) is kept as
+// Makefile.orig so custom targets can be moved to local.mk.
+func writeMakefile(cfg *ProjectConfig, preview Preview) error {
+	content, err := renderMakefile(cfg)
+	if err != nil {
+		return err
+	}
+
+	if old, err := os.ReadFile("Makefile"); err == nil && !strings.HasPrefix(string(old), makefileHeader) && !preview.Enabled() {
+		if err := os.WriteFile("Makefile.orig", old, 0644); err != nil {
+			return err
+		}
+		fmt.Println("  • Previous Makefile saved as Makefile.orig (move custom targets to local.mk)")
+	}
+
+	_, err = preview.writeFile("Makefile", content, 0644)
+	return err
+}
+
+// SyncProject regenerates the Makefile from grpc-gen.yaml. The manifest itself
+// is only rewritten when its services had to be read from an old Makefile.
+func SyncProject(preview Preview) error {
+	cfg, err := LoadProjectConfig()
+	if err != nil {
+		return err
+	}
 
-	return os.WriteFile("Makefile", []byte(content), 0644)
+	if cfg.imported {
+		if err := saveProjectConfig(cfg, preview); err != nil {
+			return err
+		}
+	}
+	return writeMakefile(cfg, preview)
 }
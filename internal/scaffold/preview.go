@@ -0,0 +1,72 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/utils"
+)
+
+// Preview makes a command render its files in memory and report them instead
+// of writing to disk
+type Preview struct {
+	DryRun bool // list the files that would be created or modified
+	Diff   bool // also print a unified diff against what is on disk
+}
+
+// Enabled reports whether files are left untouched
+func (p Preview) Enabled() bool {
+	return p.DryRun || p.Diff
+}
+
+// writeFile writes content to path (creating its directory) or, in preview
+// mode, prints "create"/"modify" and the diff. It reports whether the file
+// differs from disk.
+func (p Preview) writeFile(path string, content []byte, perm os.FileMode) (bool, error) {
+	old, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if old != nil && bytes.Equal(old, content) {
+		return false, nil
+	}
+
+	if p.Enabled() {
+		action := "modify"
+		if old == nil {
+			action = "create"
+		}
+		fmt.Printf("%-7s %s\n", action, path)
+		if p.Diff {
+			fmt.Print(utils.UnifiedDiff(path, string(old), string(content)))
+		}
+		return true, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, content, perm)
+}
+
+// removeFile deletes path or, in preview mode, prints "delete" and the diff
+func (p Preview) removeFile(path string) error {
+	old, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if p.Enabled() {
+		fmt.Printf("%-7s %s\n", "delete", path)
+		if p.Diff {
+			fmt.Print(utils.UnifiedDiff(path, string(old), ""))
+		}
+		return nil
+	}
+	return os.Remove(path)
+}
@@ -3,23 +3,28 @@ package scaffold
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
-// CreateProject initializes a new gRPC project with complete scaffolding
-func CreateProject(projectName, modulePath string) error {
+// CreateProject initializes a new gRPC project with complete scaffolding and
+// records cfg (without services, add-service adds them) as grpc-gen.yaml
+func CreateProject(projectName string, cfg ProjectConfig) error {
+	cfg.ScaffoldVersion = Version
+	cfg.Services = []ServiceConfig{}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	modulePath, dialect := cfg.Module, cfg.Dialect
+
 	fmt.Println("🔨 Creating project structure...")
 
 	// Create directories
 	dirs := []string{
 		"proto/common",
+		"proto/grpcgen",
 		"src/service",
 		"src/service/pkg",
 		"logs",
-		"scripts/types",
-		"scripts/parser",
-		"scripts/generator",
-		"scripts/utils",
-		"template",
 	}
 
 	for _, dir := range dirs {
@@ -31,55 +36,40 @@ func CreateProject(projectName, modulePath string) error {
 	fmt.Println("  ✓ Created directory structure")
 
 	// Create go.mod
-	if err := createGoMod(modulePath); err != nil {
+	if err := createGoMod(modulePath, dialect); err != nil {
 		return err
 	}
 	fmt.Println("  ✓ Created go.mod")
 
-	// Create common proto
-	if err := createCommonProto(); err != nil {
-		return err
-	}
-	fmt.Println("  ✓ Created common.proto")
-
-	// Copy pkg files from template
-	if err := copyPkgFiles(); err != nil {
-		return err
-	}
-	fmt.Println("  ✓ Copied pkg utilities")
-
-	// Copy CERTS_SETUP.md
-	if err := copyCertsSetup(); err != nil {
-		return err
-	}
-	fmt.Println("  ✓ Copied CERTS_SETUP.md")
-
-	// Copy generate-certs.sh script
-	if err := copyGenerateCertsScript(); err != nil {
+	// Record project settings for later commands
+	if err := SaveProjectConfig(&cfg); err != nil {
 		return err
 	}
-	fmt.Println("  ✓ Copied generate-certs.sh")
+	fmt.Printf("  ✓ Created %s (database: %s, TLS: %s, trace: %s)\n", ProjectConfigFile, dialect, cfg.TLS, cfg.Trace)
 
-	// Copy templates
-	if err := copyTemplates(); err != nil {
+	// Create common.proto and grpcgen/options.proto and copy the pkg
+	// utilities, CERTS_SETUP.md and generate-certs.sh, keeping a copy under
+	// .grpc-gen/base for grpc-gen upgrade
+	files, err := scaffoldFiles(modulePath, dialect)
+	if err != nil {
 		return err
 	}
-	fmt.Println("  ✓ Created templates")
-
-	// Copy generator scripts
-	if err := copyGeneratorScripts(); err != nil {
-		return err
+	for _, f := range files {
+		if err := writeScaffoldFile(f); err != nil {
+			return err
+		}
 	}
-	fmt.Println("  ✓ Created generator scripts")
+	fmt.Println("  ✓ Created common.proto and grpcgen/options.proto")
+	fmt.Println("  ✓ Copied pkg utilities, CERTS_SETUP.md and generate-certs.sh")
 
-	// Create Makefile
-	if err := createMakefile(modulePath); err != nil {
+	// Create Makefile (generated from grpc-gen.yaml)
+	if err := writeMakefile(&cfg, Preview{}); err != nil {
 		return err
 	}
 	fmt.Println("  ✓ Created Makefile")
 
 	// Create README
-	if err := createReadme(projectName, modulePath); err != nil {
+	if err := createReadme(projectName, modulePath, dialect); err != nil {
 		return err
 	}
 	fmt.Println("  ✓ Created README.md")
@@ -93,19 +83,39 @@ func CreateProject(projectName, modulePath string) error {
 	return nil
 }
 
-func createGoMod(modulePath string) error {
+// dialectDrivers is the database/sql driver module required by each database
+// server; the SQLite driver is always required
+var dialectDrivers = map[string]string{
+	"mysql":    "github.com/go-sql-driver/mysql v1.8.1",
+	"postgres": "github.com/jackc/pgx/v5 v5.7.2",
+}
+
+// goModRequires lists the modules (with versions) the pkg utilities and the
+// generated code of a project using dialect import
+func goModRequires(dialect string) []string {
+	requires := []string{
+		"github.com/google/uuid v1.6.0",
+		"github.com/joho/godotenv v1.5.1",
+		"github.com/stretchr/testify v1.9.0",
+		"google.golang.org/grpc v1.70.0",
+		"google.golang.org/protobuf v1.36.1",
+		"modernc.org/sqlite v1.34.5",
+	}
+	if module, ok := dialectDrivers[dialect]; ok {
+		requires = append(requires, module)
+	}
+	return requires
+}
+
+func createGoMod(modulePath, dialect string) error {
 	content := fmt.Sprintf(`module %s
 
 go 1.24
 
 require (
-	github.com/google/uuid v1.6.0
-	github.com/joho/godotenv v1.5.1
-	google.golang.org/grpc v1.70.0
-	google.golang.org/protobuf v1.36.1
-	github.com/go-sql-driver/mysql v1.8.1
+	%s
 )
-`, modulePath)
+`, modulePath, strings.Join(goModRequires(dialect), "\n\t"))
 
 	return os.WriteFile("go.mod", []byte(content), 0644)
 }
@@ -117,7 +127,6 @@ func createGitignore() error {
 *.dll
 *.so
 *.dylib
-gen_skeleton
 /src/service/*/main
 
 # Test binary
@@ -146,7 +155,14 @@ Thumbs.db
 	return os.WriteFile(".gitignore", []byte(content), 0644)
 }
 
-func createReadme(projectName, modulePath string) error {
+// databaseNames is the prerequisite listed in the project README per dialect
+var databaseNames = map[string]string{
+	"mysql":    "MySQL 8.0+",
+	"postgres": "PostgreSQL 13+",
+	"sqlite":   "Nothing else: SQLite is embedded (pure Go driver)",
+}
+
+func createReadme(projectName, modulePath, dialect string) error {
 	content := fmt.Sprintf(`# %s
 
 Generated gRPC microservices project with CRUD operations.
@@ -155,7 +171,7 @@ Generated gRPC microservices project with CRUD operations.
 
 - Go 1.24+
 - Protocol Buffers compiler (protoc)
-- MySQL 8.0+
+- %s
 
 ## Project Structure
 
@@ -170,8 +186,7 @@ Generated gRPC microservices project with CRUD operations.
 │       ├── database/ # Database utilities
 │       ├── logger/   # Logger utilities
 │       └── helper/   # Helper functions
-├── scripts/          # Code generation scripts
-├── template/         # Code templates
+├── template/         # Optional template overrides (grpc-gen generate --eject-templates)
 ├── env/             # Environment files
 └── docker/          # Dockerfiles
 
@@ -250,7 +265,7 @@ go build ./src/service/user
 ## License
 
 MIT
-`, projectName)
+`, projectName, databaseNames[dialect])
 
 	return os.WriteFile("README.md", []byte(content), 0644)
 }
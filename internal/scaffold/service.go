@@ -2,44 +2,80 @@ package scaffold
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/generator"
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/utils"
 )
 
-// AddService adds a new service to an existing project
-func AddService(serviceName string, port int) error {
+// AddService records a new service in grpc-gen.yaml, writes its starter proto
+// and regenerates the Makefile. The proto declares entities, or an example
+// entity named after the service when there are none. With preview enabled the
+// files are rendered in memory and only reported.
+func AddService(service ServiceConfig, entities []Entity, preview Preview) error {
 	// Check if in a valid project
 	if _, err := os.Stat("go.mod"); os.IsNotExist(err) {
 		return fmt.Errorf("not in a project directory (go.mod not found)")
 	}
 
-	serviceLower := strings.ToLower(serviceName)
-	serviceTitle := strings.Title(serviceLower)
+	// Follow the database chosen with grpc-gen init
+	project, err := LoadProjectConfig()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  • Database: %s\n", project.Dialect)
 
-	// Create proto directory
-	protoDir := filepath.Join("proto", serviceLower)
-	if err := os.MkdirAll(protoDir, 0755); err != nil {
-		return fmt.Errorf("failed to create proto directory: %w", err)
+	service.Name = NormalizeServiceName(service.Name)
+	if err := ValidateServiceName(service.Name); err != nil {
+		return err
+	}
+	if project.Service(service.Name) != nil {
+		return fmt.Errorf("service %s already exists in %s", service.Name, ProjectConfigFile)
+	}
+	if err := project.checkPortFree(service.Port); err != nil {
+		return err
+	}
+	project.Services = append(project.Services, service)
+	if err := project.Validate(); err != nil {
+		return err
 	}
 
 	// Create proto file
-	protoFile := filepath.Join(protoDir, serviceLower+".proto")
-	if err := createServiceProto(protoFile, serviceLower, serviceTitle); err != nil {
+	protoFile := filepath.Join("proto", service.Name, service.Name+".proto")
+	proto, err := createServiceProto(service.Name, toEntityName(service.Name), entities)
+	if err != nil {
+		return err
+	}
+	if _, err := preview.writeFile(protoFile, []byte(proto), 0644); err != nil {
 		return fmt.Errorf("failed to create proto file: %w", err)
 	}
-	fmt.Printf("  ✓ Created %s\n", protoFile)
 
-	// Update Makefile
-	if err := addServiceToMakefile(serviceLower, serviceTitle, port); err != nil {
+	// Record the service, then regenerate the Makefile from the manifest
+	if err := saveProjectConfig(project, preview); err != nil {
+		return fmt.Errorf("failed to update %s: %w", ProjectConfigFile, err)
+	}
+	if err := writeMakefile(project, preview); err != nil {
 		return fmt.Errorf("failed to update Makefile: %w", err)
 	}
-	fmt.Printf("  ✓ Updated Makefile\n")
+
+	if !preview.Enabled() {
+		fmt.Printf("  ✓ Created %s\n", protoFile)
+		for _, entity := range entities {
+			fmt.Printf("  ✓ Added %s (%d fields, 5 rpcs)\n", entity.Name, len(entity.Fields))
+		}
+		fmt.Printf("  ✓ Added %s to %s (port %d, TLS %s)\n", service.Name, ProjectConfigFile, service.Port, project.TLSMode(service))
+		fmt.Printf("  ✓ Regenerated Makefile\n")
+	}
 
 	return nil
 }
 
-func createServiceProto(filename, serviceLower, serviceTitle string) error {
+// createServiceProto renders the starter proto of a service with entities,
+// or with an example entity
+func createServiceProto(serviceLower, serviceTitle string, entities []Entity) (string, error) {
 	// Read module path
 	data, _ := os.ReadFile("go.mod")
 	modulePath := "mymodule"
@@ -50,12 +86,41 @@ func createServiceProto(filename, serviceLower, serviceTitle string) error {
 		}
 	}
 
+	if len(entities) > 0 {
+		protoFile := filepath.Join("proto", serviceLower, serviceLower+".proto")
+		content := fmt.Sprintf(`syntax = "proto3";
+
+package %s;
+
+option go_package = "%s/proto/%s";
+
+import "google/protobuf/timestamp.proto";
+import "proto/common/common.proto";
+// import "proto/grpcgen/options.proto"; // table/column/skip_db/filterable/sortable options
+
+// ============= Service =============
+service %sService {
+}
+`, serviceLower, modulePath, serviceLower, serviceTitle)
+
+		for _, entity := range entities {
+			if err := entity.validate(); err != nil {
+				return "", err
+			}
+			var err error
+			if content, err = addEntityToProto(protoFile, serviceLower, content, entity); err != nil {
+				return "", err
+			}
+		}
+		return content, nil
+	}
+
 	// Get entity name with proper case (e.g., user -> User, post-type -> PostType)
 	entityName := toEntityName(serviceLower)
-	entityNamePlural := entityName + "s"
+	entityNamePlural := utils.Pluralize(entityName)
 	// Get snake_case versions for field names (User -> user, PostType -> post_type)
 	entityNameSnake := toSnakeCase(entityName)
-	entityNameSnakePlural := entityNameSnake + "s"
+	entityNameSnakePlural := toSnakeCase(entityNamePlural)
 
 	content := fmt.Sprintf(`syntax = "proto3";
 
@@ -65,6 +130,7 @@ option go_package = "%s/proto/%s";
 
 import "google/protobuf/timestamp.proto";
 import "proto/common/common.proto";
+// import "proto/grpcgen/options.proto"; // table/column/skip_db/filterable/sortable options
 
 // ============= %s Entity =============
 // Example structure - uncomment and modify as needed:
@@ -130,6 +196,7 @@ message List%sResponse {
   int32 total = 2;
   int32 page = 3;
   int32 page_size = 4;
+  string next_page_token = 5;
 }
 
 // ============= Service =============
@@ -162,19 +229,14 @@ service %sService {
 		entityName, entityName, entityName,
 		entityNamePlural, entityNamePlural, entityNamePlural)
 
-	return os.WriteFile(filename, []byte(content), 0644)
+	return content, nil
 }
 
-// toEntityName converts service name to entity name
-// Examples: user -> User, post-type -> PostType, user-profile -> UserProfile
-func toEntityName(serviceName string) string {
-	parts := strings.Split(serviceName, "-")
-	for i, part := range parts {
-		if len(part) > 0 {
-			parts[i] = strings.ToUpper(part[:1]) + part[1:]
-		}
-	}
-	return strings.Join(parts, "")
+// toEntityName converts a service or field name to PascalCase the way the
+// generator names Go identifiers (utils.ToCamelCase)
+// Examples: user -> User, post_type -> PostType, user-profile -> UserProfile
+func toEntityName(name string) string {
+	return utils.ToCamelCase(strings.ReplaceAll(name, "-", "_"))
 }
 
 // toSnakeCase converts PascalCase to snake_case
@@ -190,48 +252,87 @@ func toSnakeCase(s string) string {
 	return strings.ToLower(result.String())
 }
 
-func addServiceToMakefile(serviceLower, serviceTitle string, port int) error {
-	// Read existing Makefile
-	data, err := os.ReadFile("Makefile")
+// RemovedPath is a file or directory RemoveService deletes
+type RemovedPath struct {
+	Path      string
+	Files     int // regular files below Path
+	UserFiles int // files without the generated header (hooks, env, ...)
+}
+
+// PlanRemoveService lists what removing a service deletes: everything
+// add-service and grpc-gen generate created for it, and its migrations. With
+// keepProto the proto directory stays.
+func PlanRemoveService(name string, keepProto bool) ([]RemovedPath, error) {
+	project, err := LoadProjectConfig()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	content := string(data)
-
-	// Check if service already exists
-	if strings.Contains(content, fmt.Sprintf("proto-%s:", serviceLower)) {
-		return fmt.Errorf("service %s already exists in Makefile", serviceLower)
+	name = NormalizeServiceName(name)
+	candidates := []string{
+		filepath.Join("src", "service", name),
+		filepath.Join("migrations", name),
 	}
-
-	// Find insertion point for proto target
-	protoInsert := strings.Index(content, "# Generate all protos")
-	if protoInsert == -1 {
-		return fmt.Errorf("could not find proto insertion point in Makefile")
+	if !keepProto {
+		candidates = append(candidates, filepath.Join("proto", name))
 	}
 
-	// Add proto target
-	protoTarget := fmt.Sprintf("\nproto-%s:\n\t$(PROTOC) proto/%s/%s.proto\n", serviceLower, serviceLower, serviceLower)
-	content = content[:protoInsert] + protoTarget + "\n" + content[protoInsert:]
+	var paths []RemovedPath
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		removed := RemovedPath{Path: path}
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			removed.Files++
+			if generated, _ := generator.IsGenerated(p); !generated && !strings.HasSuffix(p, ".pb.go") && filepath.Ext(p) != ".sql" {
+				removed.UserFiles++
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, removed)
+	}
 
-	// Update all: target
-	allTarget := fmt.Sprintf("all: proto-common proto-%s", serviceLower)
-	content = strings.Replace(content, "all: proto-common", allTarget, 1)
+	if project.Service(name) == nil && len(paths) == 0 {
+		return nil, fmt.Errorf("service %s not found in %s", name, ProjectConfigFile)
+	}
+	return paths, nil
+}
 
-	// Find insertion point for gen target
-	genInsert := strings.Index(content, "# Generate all service skeletons")
-	if genInsert == -1 {
-		return fmt.Errorf("could not find gen insertion point in Makefile")
+// RemoveService deletes the paths planned by PlanRemoveService, drops the
+// service from grpc-gen.yaml and regenerates the Makefile
+func RemoveService(name string, paths []RemovedPath) error {
+	project, err := LoadProjectConfig()
+	if err != nil {
+		return err
 	}
 
-	// Add gen target
-	genTarget := fmt.Sprintf("\ngen-%s: gen-tool proto-%s\n\t$(GEN) %s %sService %d\n",
-		serviceLower, serviceLower, serviceLower, serviceTitle, port)
-	content = content[:genInsert] + genTarget + "\n" + content[genInsert:]
+	name = NormalizeServiceName(name)
+	services := project.Services[:0]
+	for _, s := range project.Services {
+		if s.Name != name {
+			services = append(services, s)
+		}
+	}
+	project.Services = services
 
-	// Update gen-all target
-	genAllTarget := fmt.Sprintf("gen-all: gen-tool all gen-%s", serviceLower)
-	content = strings.Replace(content, "gen-all: gen-tool all", genAllTarget, 1)
+	for _, path := range paths {
+		if err := os.RemoveAll(path.Path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path.Path, err)
+		}
+	}
 
-	return os.WriteFile("Makefile", []byte(content), 0644)
+	if err := saveProjectConfig(project, Preview{}); err != nil {
+		return fmt.Errorf("failed to update %s: %w", ProjectConfigFile, err)
+	}
+	if err := writeMakefile(project, Preview{}); err != nil {
+		return fmt.Errorf("failed to update Makefile: %w", err)
+	}
+	return nil
 }
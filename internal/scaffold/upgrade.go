@@ -0,0 +1,597 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/thailyhcmut/grpc-gen/internal/scaffold/assets/scripts/utils"
+)
+
+// UpgradeChange is a file or directory changed by grpc-gen upgrade
+type UpgradeChange struct {
+	Path string
+	// add, update, merge, move, remove, keep, or the two that need a look:
+	// conflict (merge conflict markers written) and replace (no merge base,
+	// the previous version is kept as .orig)
+	Action string
+	Note   string
+}
+
+// NeedsReview reports whether the user has to look at the change
+func (c UpgradeChange) NeedsReview() bool {
+	return c.Action == "conflict" || c.Action == "replace"
+}
+
+// UpgradeReport describes what grpc-gen upgrade did
+type UpgradeReport struct {
+	From, To string
+	Steps    []string
+	Changes  []UpgradeChange
+}
+
+// Review returns the changes that need the user's attention
+func (r *UpgradeReport) Review() []UpgradeChange {
+	var review []UpgradeChange
+	for _, c := range r.Changes {
+		if c.NeedsReview() {
+			review = append(review, c)
+		}
+	}
+	return review
+}
+
+// upgradeStep migrates the layout of projects older than version. Steps run
+// in order and must be idempotent: they check what is on disk.
+type upgradeStep struct {
+	version     string
+	description string
+	run         func(u *upgrader) error
+}
+
+var upgradeSteps = []upgradeStep{
+	{"v0.4.0", "Move src/pkg to src/service/pkg and rewrite imports", moveSharedPkg},
+	{"v0.4.0", "Remove the per-project generator (scripts/, gen_skeleton)", removeGeneratorScripts},
+	{"v0.4.0", "Retire the template copies made by init", retireTemplateCopies},
+}
+
+// unrecordedVersion stands for projects created before grpc-gen.yaml recorded
+// the scaffold version
+const unrecordedVersion = "v0.3.0"
+
+// Upgrade brings the project in the current directory to Version: it runs the
+// upgrade steps newer than the project, three-way merges the pkg files (and
+// ejected templates) with the user's edits, regenerates the Makefile and
+// records Version in grpc-gen.yaml. Nothing is written before every step
+// succeeded; with preview enabled nothing is written at all.
+func Upgrade(preview Preview) (*UpgradeReport, error) {
+	cfg, err := LoadProjectConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	from := cfg.ScaffoldVersion
+	if from == "" {
+		from = unrecordedVersion
+	}
+	report := &UpgradeReport{From: from, To: Version}
+
+	switch c := compareVersions(from, Version); {
+	case c > 0:
+		return nil, fmt.Errorf("project is at %s, newer than this grpc-gen (%s): upgrade grpc-gen first", from, Version)
+	case c == 0:
+		return report, nil
+	}
+
+	u := &upgrader{cfg: cfg, report: report, staged: make(map[string]*stagedFile), bases: make(map[string][]byte)}
+	if u.previous, err = previousScaffoldFiles(from, cfg.Module); err != nil {
+		return nil, err
+	}
+	for _, step := range upgradeSteps {
+		if compareVersions(from, step.version) >= 0 {
+			continue
+		}
+		if err := step.run(u); err != nil {
+			return nil, fmt.Errorf("%s: %w", step.description, err)
+		}
+		report.Steps = append(report.Steps, step.description)
+	}
+
+	// Every upgrade refreshes the scaffold files and the generated Makefile
+	if err := refreshScaffoldFiles(u); err != nil {
+		return nil, fmt.Errorf("refresh scaffold files: %w", err)
+	}
+	report.Steps = append(report.Steps, "Merge pkg files and templates with "+Version)
+
+	if err := requireModules(u); err != nil {
+		return nil, fmt.Errorf("update go.mod: %w", err)
+	}
+
+	cfg.ScaffoldVersion = Version
+	if err := u.writeManifest(); err != nil {
+		return nil, err
+	}
+	report.Steps = append(report.Steps, "Regenerate the Makefile and record "+Version+" in "+ProjectConfigFile)
+
+	return report, u.commit(preview)
+}
+
+// compareVersions compares two vMAJOR.MINOR.PATCH versions
+func compareVersions(a, b string) int {
+	pa, pb := parseVersion(a), parseVersion(b)
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func parseVersion(v string) [3]int {
+	var parts [3]int
+	for i, s := range strings.SplitN(strings.TrimPrefix(v, "v"), ".", 3) {
+		parts[i], _ = strconv.Atoi(s)
+	}
+	return parts
+}
+
+// stagedFile is a write (or a delete) upgrade applies once every step succeeded
+type stagedFile struct {
+	content []byte
+	perm    os.FileMode
+	deleted bool
+}
+
+// upgrader stages the changes of the upgrade steps: they read the project
+// through it and see the changes of the steps before them
+type upgrader struct {
+	cfg    *ProjectConfig
+	report *UpgradeReport
+	staged map[string]*stagedFile
+	order  []string
+	// bases are the new merge bases, written to baseDir
+	bases map[string][]byte
+	// previous are the files the project's release wrote, the merge bases
+	// of projects older than baseDir
+	previous map[string][]byte
+	// emptied are directories whose files were all moved or removed
+	emptied []string
+}
+
+func (u *upgrader) stage(path string, f *stagedFile) {
+	if _, ok := u.staged[path]; !ok {
+		u.order = append(u.order, path)
+	}
+	u.staged[path] = f
+}
+
+func (u *upgrader) write(path string, content []byte, perm os.FileMode) {
+	u.stage(path, &stagedFile{content: content, perm: perm})
+}
+
+func (u *upgrader) remove(path string) {
+	u.stage(path, &stagedFile{deleted: true})
+}
+
+// read returns the content of path as the previous steps left it
+func (u *upgrader) read(path string) ([]byte, error) {
+	if f, ok := u.staged[path]; ok {
+		if f.deleted {
+			return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+		}
+		return f.content, nil
+	}
+	return os.ReadFile(path)
+}
+
+func (u *upgrader) exists(path string) bool {
+	_, err := u.read(path)
+	return err == nil
+}
+
+func (u *upgrader) record(path, action, note string) {
+	u.report.Changes = append(u.report.Changes, UpgradeChange{Path: path, Action: action, Note: note})
+}
+
+// files lists the files below dir on disk with their permissions
+func (u *upgrader) files(dir string) (map[string]os.FileMode, error) {
+	files := make(map[string]os.FileMode)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files[path] = info.Mode().Perm()
+		return nil
+	})
+	return files, err
+}
+
+// goFiles lists the .go files of the project, staged ones included, without
+// hidden directories and the old generator
+func (u *upgrader) goFiles() ([]string, error) {
+	seen := make(map[string]bool)
+	err := filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != "." && (strings.HasPrefix(d.Name(), ".") || path == "scripts" || path == "vendor") {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".go") {
+			seen[path] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for path := range u.staged {
+		if strings.HasSuffix(path, ".go") {
+			seen[path] = true
+		}
+	}
+
+	var paths []string
+	for path := range seen {
+		if u.exists(path) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// commit applies the staged changes, new merge bases included, and removes
+// the directories left empty
+func (u *upgrader) commit(preview Preview) error {
+	for _, path := range u.order {
+		f := u.staged[path]
+		if f.deleted {
+			if err := preview.removeFile(path); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := preview.writeFile(path, f.content, f.perm); err != nil {
+			return err
+		}
+	}
+	if preview.Enabled() {
+		return nil
+	}
+
+	for path, content := range u.bases {
+		path = filepath.Join(baseDir, path)
+		if content == nil {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return err
+		}
+	}
+
+	for _, dir := range u.emptied {
+		files, err := u.files(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if len(files) == 0 {
+			if err := os.RemoveAll(dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// moveSharedPkg moves the shared packages of very old projects from src/pkg
+// to src/service/pkg, where the generated code imports them from
+func moveSharedPkg(u *upgrader) error {
+	oldDir, newDir := filepath.Join("src", "pkg"), filepath.Join("src", "service", "pkg")
+	if info, err := os.Stat(oldDir); err != nil || !info.IsDir() {
+		return nil
+	}
+	if _, err := os.Stat(newDir); err == nil {
+		u.record(oldDir, "keep", newDir+" exists too: merge the packages by hand")
+		return nil
+	}
+
+	files, err := u.files(oldDir)
+	if err != nil {
+		return err
+	}
+	for path, perm := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(oldDir, path)
+		u.write(filepath.Join(newDir, rel), content, perm)
+		u.remove(path)
+	}
+	u.emptied = append(u.emptied, oldDir)
+	u.record(oldDir, "move", "to "+newDir)
+
+	from, to := `"`+u.cfg.Module+"/src/pkg/", `"`+u.cfg.Module+"/src/service/pkg/"
+	paths, err := u.goFiles()
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		content, err := u.read(path)
+		if err != nil {
+			return err
+		}
+		if !bytes.Contains(content, []byte(from)) {
+			continue
+		}
+		u.write(path, bytes.ReplaceAll(content, []byte(from), []byte(to)), 0644)
+		u.record(path, "update", "imports src/service/pkg")
+	}
+	return nil
+}
+
+// removeGeneratorScripts removes the copy of the generator init used to put
+// into scripts/: grpc-gen generate runs the one built into the CLI
+func removeGeneratorScripts(u *upgrader) error {
+	goMod, err := os.ReadFile(filepath.Join("scripts", "go.mod"))
+	if err != nil || !bytes.Contains(goMod, []byte("module gen_skeleton")) {
+		return nil
+	}
+
+	files, err := u.files("scripts")
+	if err != nil {
+		return err
+	}
+	for path := range files {
+		u.remove(path)
+	}
+	u.emptied = append(u.emptied, "scripts")
+	u.record("scripts", "remove", "the generator is built into grpc-gen (grpc-gen generate)")
+
+	if _, err := os.Stat("gen_skeleton"); err == nil {
+		u.remove("gen_skeleton")
+		u.record("gen_skeleton", "remove", "")
+	}
+	return nil
+}
+
+// retireTemplateCopies handles the templates init copied into template/
+// before they were built into grpc-gen. A copy equal to the template init
+// wrote or to the built-in one is removed; a customized one is set aside as
+// .orig and the built-in template takes over.
+func retireTemplateCopies(u *upgrader) error {
+	for _, name := range templateNames {
+		path := filepath.Join("template", name)
+		content, err := u.read(path)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(baseDir, path)); err == nil {
+			continue // ejected: merged by refreshScaffoldFiles
+		}
+
+		builtin, err := assetsFS.ReadFile("assets/" + name)
+		if err != nil {
+			return err
+		}
+		u.remove(path)
+		if previous, ok := u.previous[path]; bytes.Equal(content, builtin) || ok && bytes.Equal(content, previous) {
+			u.record(path, "remove", "unchanged, the built-in template is used now")
+			continue
+		}
+		u.write(path+".orig", content, 0644)
+		u.record(path, "replace", "the built-in template is used now, yours is kept as "+path+
+			".orig: re-apply your changes after grpc-gen generate --eject-templates")
+	}
+	return nil
+}
+
+// refreshScaffoldFiles brings the pkg files, certificate helpers and ejected
+// templates to this version, three-way merged with the user's edits
+func refreshScaffoldFiles(u *upgrader) error {
+	files, err := scaffoldFiles(u.cfg.Module, u.cfg.Dialect)
+	if err != nil {
+		return err
+	}
+	for _, name := range templateNames {
+		path := filepath.Join("template", name)
+		if _, err := os.Stat(filepath.Join(baseDir, path)); err != nil || !u.exists(path) {
+			continue
+		}
+		builtin, err := assetsFS.ReadFile("assets/" + name)
+		if err != nil {
+			return err
+		}
+		files = append(files, scaffoldFile{path: path, content: builtin, perm: 0644})
+	}
+
+	current := make(map[string]bool)
+	for _, f := range files {
+		current[f.path] = true
+		if err := u.refresh(f); err != nil {
+			return err
+		}
+	}
+
+	// Files this version no longer ships
+	bases, err := u.files(baseDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	dropped := make(map[string]bool)
+	for path := range bases {
+		rel, _ := filepath.Rel(baseDir, path)
+		dropped[rel] = !current[rel]
+	}
+	for path := range u.previous {
+		if _, ok := dropped[path]; !ok {
+			dropped[path] = !current[path] && path != "Makefile"
+		}
+	}
+	var paths []string
+	for path, ok := range dropped {
+		if ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		u.bases[path] = nil
+		base, err := u.base(path)
+		if err != nil {
+			return err
+		}
+		content, err := u.read(path)
+		switch {
+		case err != nil:
+		case bytes.Equal(content, base):
+			u.remove(path)
+			u.record(path, "remove", "no longer part of the scaffold")
+		default:
+			u.record(path, "keep", "no longer part of the scaffold, kept because you edited it")
+		}
+	}
+	return nil
+}
+
+// refresh merges one scaffold file: base is what grpc-gen wrote last time,
+// the file on disk has the user's edits and f is this version
+func (u *upgrader) refresh(f scaffoldFile) error {
+	u.bases[f.path] = f.content
+
+	content, err := u.read(f.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	base, baseErr := u.base(f.path)
+
+	switch {
+	case content == nil && baseErr == nil:
+		u.record(f.path, "keep", "deleted locally, not restored")
+	case content == nil:
+		u.write(f.path, f.content, f.perm)
+		u.record(f.path, "add", "")
+	case bytes.Equal(content, f.content):
+	case baseErr != nil:
+		u.write(f.path, f.content, f.perm)
+		u.write(f.path+".orig", content, 0644)
+		u.record(f.path, "replace", "no merge base recorded, your version is kept as "+f.path+".orig")
+	default:
+		merged, conflicts := utils.Merge3(string(base), string(content), string(f.content), "yours", "grpc-gen "+Version)
+		u.write(f.path, []byte(merged), f.perm)
+		switch {
+		case conflicts > 0:
+			u.record(f.path, "conflict", fmt.Sprintf("%d conflict(s): resolve the <<<<<<< markers", conflicts))
+		case bytes.Equal(content, base):
+			u.record(f.path, "update", "")
+		default:
+			u.record(f.path, "merge", "merged with your changes")
+		}
+	}
+	return nil
+}
+
+// base returns the merge base of path: the copy in baseDir or, for projects
+// older than it, the file their release wrote
+func (u *upgrader) base(path string) ([]byte, error) {
+	base, err := os.ReadFile(filepath.Join(baseDir, path))
+	if previous, ok := u.previous[path]; err != nil && ok {
+		return previous, nil
+	}
+	return base, err
+}
+
+// requireModules adds the modules the refreshed pkg files import and go.mod
+// does not require yet; go mod tidy fills in go.sum
+func requireModules(u *upgrader) error {
+	goMod, err := u.read("go.mod")
+	if err != nil {
+		return err
+	}
+	requires := make(map[string]bool)
+	for _, m := range requirePattern.FindAllStringSubmatch(string(goMod), -1) {
+		requires[m[1]] = true
+	}
+
+	var missing, modules []string
+	for _, require := range goModRequires(u.cfg.Dialect) {
+		if module := strings.Fields(require)[0]; !requires[module] {
+			missing = append(missing, require)
+			modules = append(modules, module)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	content := fmt.Sprintf("%s\n\nrequire (\n\t%s\n)\n", bytes.TrimRight(goMod, "\n"), strings.Join(missing, "\n\t"))
+	u.write("go.mod", []byte(content), 0644)
+	u.record("go.mod", "update", "requires "+strings.Join(modules, ", ")+": run go mod tidy")
+	return nil
+}
+
+// writeManifest stages grpc-gen.yaml and the Makefile generated from it. A
+// Makefile from before it was generated is kept as Makefile.orig.
+func (u *upgrader) writeManifest() error {
+	manifest, err := renderProjectConfig(u.cfg)
+	if err != nil {
+		return err
+	}
+	u.write(ProjectConfigFile, manifest, 0644)
+
+	makefile, err := renderMakefile(u.cfg)
+	if err != nil {
+		return err
+	}
+	if old, err := u.read("Makefile"); err == nil && !bytes.HasPrefix(old, []byte(makefileHeader)) {
+		if init, ok := u.previous["Makefile"]; ok && bytes.Equal(old, legacyMakefile(init, old)) {
+			u.record("Makefile", "update", "generated from "+ProjectConfigFile+" now")
+		} else {
+			u.write("Makefile.orig", old, 0644)
+			u.record("Makefile", "replace", "generated from "+ProjectConfigFile+" now, the old one is kept as Makefile.orig (move custom targets to local.mk)")
+		}
+	}
+	u.write("Makefile", makefile, 0644)
+	return nil
+}
+
+// legacyMakefile rebuilds the Makefile of a project from before it was
+// generated: the one init wrote, with the targets add-service inserted for
+// each service of old. A Makefile equal to it has no edits of the user.
+func legacyMakefile(init, old []byte) []byte {
+	const protoMarker, genMarker = "# Generate all protos", "# Generate all service skeletons"
+	content := string(init)
+	if !strings.Contains(content, protoMarker) || !strings.Contains(content, genMarker) {
+		return init
+	}
+	for _, m := range legacyGenTarget.FindAllStringSubmatch(string(old), -1) {
+		name, goService, port := m[1], m[2], m[3]
+
+		i := strings.Index(content, protoMarker)
+		content = content[:i] + fmt.Sprintf("\nproto-%[1]s:\n\t$(PROTOC) proto/%[1]s/%[1]s.proto\n\n", name) + content[i:]
+		content = strings.Replace(content, "all: proto-common", "all: proto-common proto-"+name, 1)
+
+		j := strings.Index(content, genMarker)
+		content = content[:j] + fmt.Sprintf("\ngen-%[1]s: gen-tool proto-%[1]s\n\t$(GEN) %[1]s %[2]s %[3]s\n\n", name, goService, port) + content[j:]
+		content = strings.Replace(content, "gen-all: gen-tool all", "gen-all: gen-tool all gen-"+name, 1)
+	}
+	return []byte(content)
+}
@@ -0,0 +1,165 @@
+package scaffold
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// v030Makefile is the Makefile grpc-gen v0.3.0 wrote for init and
+// add-service user, add-service course
+const v030Makefile = `PROTOC = protoc --go_out=. --go_opt=paths=source_relative \
+               --go-grpc_out=. --go-grpc_opt=paths=source_relative
+GEN_BIN = ./gen_skeleton
+GEN = $(GEN_BIN)
+
+# Build code generator binary
+gen-tool:
+	cd scripts && go build -o ../$(GEN_BIN) gen_skeleton.go
+
+# Service port mappings
+# Add your services here with their ports
+
+# Proto generation
+proto-common:
+	$(PROTOC) proto/common/common.proto
+
+
+proto-user:
+	$(PROTOC) proto/user/user.proto
+
+
+proto-course:
+	$(PROTOC) proto/course/course.proto
+
+# Generate all protos
+all: proto-common proto-course proto-user
+
+
+gen-user: gen-tool proto-user
+	$(GEN) user UserService 50051
+
+
+gen-course: gen-tool proto-course
+	$(GEN) course CourseService 50052
+
+# Generate all service skeletons
+gen-all: gen-tool all gen-course gen-user
+
+# Clean generated files
+clean:
+	rm -rf src/service/*
+	rm -f gen_skeleton
+
+# Clean everything including proto generated files
+clean-all: clean
+	find proto -name "*.pb.go" -delete
+
+.PHONY: gen-tool proto-common all gen-all clean clean-all
+`
+
+const v030GoMod = `module example.com/demo
+
+go 1.24
+
+require (
+	github.com/google/uuid v1.6.0
+	github.com/joho/godotenv v1.5.1
+	google.golang.org/grpc v1.70.0
+	google.golang.org/protobuf v1.36.1
+	github.com/go-sql-driver/mysql v1.8.1
+)
+`
+
+// TestUpgradeFromV030 upgrades a project as grpc-gen v0.3.0 created it: the
+// untouched files are updated without .orig copies, the protos the pkg
+// helpers need are written and the project builds
+func TestUpgradeFromV030(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	files, err := previousScaffoldFiles("v0.3.0", "example.com/demo")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("v0.3.0 scaffold files: %d, %v", len(files), err)
+	}
+	files["Makefile"] = []byte(v030Makefile)
+	files["go.mod"] = []byte(v030GoMod)
+	files[filepath.Join("scripts", "go.mod")] = []byte("module gen_skeleton\n\ngo 1.24\n")
+	for path, content := range files {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// An edit of the user is merged, not replaced
+	readme := filepath.Join("src", "service", "pkg", "README.md")
+	os.WriteFile(readme, append([]byte("Our notes.\n\n"), files[readme]...), 0644)
+
+	report, err := Upgrade(Preview{})
+	if err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+	actions := make(map[string]string)
+	for _, c := range report.Changes {
+		actions[c.Path] = c.Action
+		if c.NeedsReview() {
+			t.Errorf("%s: %s (%s), want no review", c.Path, c.Action, c.Note)
+		}
+	}
+	want := map[string]string{
+		filepath.Join("proto", "common", "common.proto"):                 "update",
+		filepath.Join("proto", "grpcgen", "options.proto"):               "add",
+		filepath.Join("src", "service", "pkg", "helper", "filter.go"):    "update",
+		filepath.Join("src", "service", "pkg", "helper", "pagetoken.go"): "add",
+		filepath.Join("template", "crud_handler.tmpl"):                   "remove",
+		readme:     "merge",
+		"scripts":  "remove",
+		"go.mod":   "update",
+		"Makefile": "update",
+	}
+	for path, action := range want {
+		if actions[path] != action {
+			t.Errorf("%s: action %q, want %q", path, actions[path], action)
+		}
+	}
+	filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && strings.HasSuffix(path, ".orig") {
+			t.Errorf("%s written", path)
+		}
+		return err
+	})
+
+	cfg, err := LoadProjectConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Services) != 2 || cfg.Services[0].Name != "user" || cfg.Services[1].Port != 50052 {
+		t.Errorf("services = %+v, want user and course from the Makefile", cfg.Services)
+	}
+
+	// Building needs protoc and the modules of go.mod
+	if testing.Short() {
+		t.Skip("building the upgraded project in short mode")
+	}
+	for _, tool := range []string{"protoc", "protoc-gen-go"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("building the upgraded project needs %s", tool)
+		}
+	}
+	run := func(name string, args ...string) (string, error) {
+		out, err := exec.Command(name, args...).CombinedOutput()
+		return strings.TrimSpace(string(out)), err
+	}
+	if out, err := run("protoc", "--go_out=.", "--go_opt=paths=source_relative",
+		"proto/common/common.proto", "proto/grpcgen/options.proto"); err != nil {
+		t.Fatalf("protoc: %v\n%s", err, out)
+	}
+	if out, err := run("go", "mod", "tidy"); err != nil {
+		t.Skipf("go mod tidy: %v\n%s", err, out)
+	}
+	if out, err := run("go", "vet", "./..."); err != nil {
+		t.Fatalf("upgraded project does not build: %v\n%s", err, out)
+	}
+}
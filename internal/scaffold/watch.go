@@ -0,0 +1,335 @@
+package scaffold
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// protocFlags are the flags of PROTOC in the generated Makefile
+var protocFlags = []string{
+	"--go_out=.", "--go_opt=paths=source_relative",
+	"--go-grpc_out=.", "--go-grpc_opt=paths=source_relative",
+}
+
+// WatchOptions configures Watch
+type WatchOptions struct {
+	// Services to regenerate, every service of grpc-gen.yaml when empty
+	Services []string
+	// Run lists services to build, start and restart after every change of
+	// their code
+	Run []string
+	// Interval between two scans of the watched files
+	Interval time.Duration
+}
+
+// Watch scans proto/, template/ and grpc-gen.yaml every opts.Interval until
+// ctx is done, and regenerates the services a change affects: protoc and
+// grpc-gen generate for an edited service proto, every service for templates,
+// shared protos and the manifest (which also regenerates the Makefile).
+// Errors are printed and watching goes on. The services of opts.Run are
+// rebuilt and restarted after they were regenerated or their Go code changed.
+func Watch(ctx context.Context, opts WatchOptions) error {
+	cfg, err := LoadProjectConfig()
+	if err != nil {
+		return err
+	}
+	for _, name := range slices.Concat(opts.Services, opts.Run) {
+		if cfg.Service(name) == nil {
+			return fmt.Errorf("service %s is not in %s", name, ProjectConfigFile)
+		}
+	}
+
+	// The generator exits on errors, so every run gets a process of its own
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	binDir, err := os.MkdirTemp("", "grpc-gen-watch")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(binDir)
+
+	w := &watcher{opts: opts, cfg: cfg, self: self, binDir: binDir, running: make(map[string]*process)}
+	defer w.stopAll()
+
+	for _, name := range opts.Run {
+		w.restart(name)
+	}
+	w.logf("👀 Watching proto/, template/ and %s (Ctrl+C to stop)", ProjectConfigFile)
+
+	files := w.scan()
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		next := w.scan()
+		if maps.Equal(files, next) {
+			continue
+		}
+		// Let editors finish writing before reading the files
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(opts.Interval):
+			}
+			settled := w.scan()
+			if maps.Equal(next, settled) {
+				break
+			}
+			next = settled
+		}
+
+		w.handle(changedFiles(files, next))
+		// Files written by protoc and the generator are not changes
+		files = w.scan()
+	}
+}
+
+// fileState tells two versions of a file apart
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func changedFiles(old, new map[string]fileState) []string {
+	var changed []string
+	for path, state := range new {
+		if old[path] != state {
+			changed = append(changed, path)
+		}
+	}
+	for path := range old {
+		if _, ok := new[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+type watcher struct {
+	opts    WatchOptions
+	cfg     *ProjectConfig
+	self    string
+	binDir  string
+	running map[string]*process
+}
+
+// process is a running service binary
+type process struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+func (w *watcher) logf(format string, args ...any) {
+	fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), fmt.Sprintf(format, args...))
+}
+
+// services returns the services to regenerate
+func (w *watcher) services() []string {
+	if len(w.opts.Services) > 0 {
+		return w.opts.Services
+	}
+	names := make([]string, len(w.cfg.Services))
+	for i, s := range w.cfg.Services {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// scan returns the state of the watched files: the protos, templates and
+// manifest, and the Go code and env files of the running services
+func (w *watcher) scan() map[string]fileState {
+	files := make(map[string]fileState)
+	add := func(root string, match func(path string) bool) {
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !match(path) {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				files[path] = fileState{info.ModTime(), info.Size()}
+			}
+			return nil
+		})
+	}
+	all := func(string) bool { return true }
+	code := func(path string) bool {
+		return strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".env")
+	}
+
+	add(ProjectConfigFile, all)
+	add("proto", func(path string) bool { return strings.HasSuffix(path, ".proto") })
+	add("template", all)
+	if len(w.opts.Run) > 0 {
+		add(filepath.Join("src", "service", "pkg"), code)
+		for _, name := range w.opts.Run {
+			add(filepath.Join("src", "service", name), code)
+		}
+	}
+	return files
+}
+
+// handle regenerates what the changed files affect and restarts the running
+// services whose code changed
+func (w *watcher) handle(changed []string) {
+	var manifest, templates bool
+	var shared []string
+	affected := make(map[string]bool)
+	rebuild := make(map[string]bool)
+
+	for _, path := range changed {
+		parts := strings.Split(filepath.ToSlash(path), "/")
+		switch {
+		case path == ProjectConfigFile:
+			manifest = true
+		case parts[0] == "template":
+			templates = true
+		case parts[0] == "proto" && len(parts) > 2:
+			if parts[1] == "common" || parts[1] == "grpcgen" {
+				shared = append(shared, path)
+			} else {
+				affected[parts[1]] = true
+			}
+		case parts[0] == "src" && len(parts) > 3:
+			if parts[2] == "pkg" {
+				for _, name := range w.opts.Run {
+					rebuild[name] = true
+				}
+			} else {
+				rebuild[parts[2]] = true
+			}
+		}
+	}
+	w.logf("Changed: %s", strings.Join(changed, ", "))
+
+	if manifest {
+		cfg, err := LoadProjectConfig()
+		if err != nil {
+			w.logf("✗ %v", err)
+			return
+		}
+		w.cfg = cfg
+		if err := writeMakefile(cfg, Preview{}); err != nil {
+			w.logf("✗ Makefile: %v", err)
+			return
+		}
+		w.logf("✓ Makefile regenerated from %s", ProjectConfigFile)
+	}
+
+	if existing := slices.DeleteFunc(shared, func(path string) bool {
+		_, err := os.Stat(path)
+		return err != nil
+	}); len(existing) > 0 {
+		if err := w.run("protoc", slices.Concat(protocFlags, existing)...); err != nil {
+			w.logf("✗ protoc %s", strings.Join(existing, " "))
+			return
+		}
+	}
+
+	all := manifest || templates || len(shared) > 0
+	for _, name := range w.services() {
+		if !all && !affected[name] {
+			continue
+		}
+		if w.cfg.Service(name) == nil {
+			w.logf("✗ %s: not in %s", name, ProjectConfigFile)
+			continue
+		}
+
+		start := time.Now()
+		proto := filepath.Join("proto", name, name+".proto")
+		if err := w.run("protoc", append(slices.Clone(protocFlags), proto)...); err != nil {
+			w.logf("✗ %s: protoc failed", name)
+			continue
+		}
+		if err := w.run(w.self, "generate", name); err != nil {
+			w.logf("✗ %s: generation failed", name)
+			continue
+		}
+		w.logf("✓ %s regenerated (%s)", name, time.Since(start).Round(time.Millisecond))
+		rebuild[name] = true
+	}
+
+	for _, name := range w.opts.Run {
+		if rebuild[name] {
+			w.restart(name)
+		}
+	}
+}
+
+// run runs a command and prints its output when it fails
+func (w *watcher) run(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+	return err
+}
+
+// restart builds a service and replaces its running binary; the old one keeps
+// running when the build fails
+func (w *watcher) restart(name string) {
+	bin := filepath.Join(w.binDir, name)
+	if err := w.run("go", "build", "-o", bin, "./"+filepath.ToSlash(filepath.Join("src", "service", name))); err != nil {
+		w.logf("✗ %s: build failed", name)
+		return
+	}
+	w.stop(name)
+
+	// Services read ./<name>.env from their directory
+	cmd := exec.Command(bin)
+	cmd.Dir = filepath.Join("src", "service", name)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		w.logf("✗ %s: %v", name, err)
+		return
+	}
+	p := &process{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		cmd.Wait()
+		close(p.done)
+	}()
+	w.running[name] = p
+	w.logf("▶ %s started (pid %d)", name, cmd.Process.Pid)
+}
+
+// stop interrupts the binary of a service, and kills it when it has not
+// exited after 5 seconds
+func (w *watcher) stop(name string) {
+	p := w.running[name]
+	if p == nil {
+		return
+	}
+	delete(w.running, name)
+
+	p.cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-p.done:
+	case <-time.After(5 * time.Second):
+		p.cmd.Process.Kill()
+		<-p.done
+	}
+}
+
+func (w *watcher) stopAll() {
+	for name := range w.running {
+		w.stop(name)
+	}
+}
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
 // 	protoc-gen-go v1.36.10
-// 	protoc        v3.21.12
+// 	protoc        (unknown)
 // source: proto/common/common.proto
 
 package common
@@ -25,18 +25,23 @@ const (
 type FilterOperator int32
 
 const (
-	FilterOperator_EQUAL              FilterOperator = 0  // =
-	FilterOperator_NOT_EQUAL          FilterOperator = 1  // !=
-	FilterOperator_GREATER_THAN       FilterOperator = 2  // >
-	FilterOperator_GREATER_THAN_EQUAL FilterOperator = 3  // >=
-	FilterOperator_LESS_THAN          FilterOperator = 4  // <
-	FilterOperator_LESS_THAN_EQUAL    FilterOperator = 5  // <=
-	FilterOperator_LIKE               FilterOperator = 6  // LIKE %value%
-	FilterOperator_IN                 FilterOperator = 7  // IN (val1, val2, ...)
-	FilterOperator_NOT_IN             FilterOperator = 8  // NOT IN
-	FilterOperator_IS_NULL            FilterOperator = 9  // IS NULL
-	FilterOperator_IS_NOT_NULL        FilterOperator = 10 // IS NOT NULL
-	FilterOperator_BETWEEN            FilterOperator = 11 // BETWEEN val1 AND val2
+	FilterOperator_EQUAL                  FilterOperator = 0  // =
+	FilterOperator_NOT_EQUAL              FilterOperator = 1  // !=
+	FilterOperator_GREATER_THAN           FilterOperator = 2  // >
+	FilterOperator_GREATER_THAN_EQUAL     FilterOperator = 3  // >=
+	FilterOperator_LESS_THAN              FilterOperator = 4  // <
+	FilterOperator_LESS_THAN_EQUAL        FilterOperator = 5  // <=
+	FilterOperator_LIKE                   FilterOperator = 6  // LIKE %value%
+	FilterOperator_IN                     FilterOperator = 7  // IN (val1, val2, ...)
+	FilterOperator_NOT_IN                 FilterOperator = 8  // NOT IN
+	FilterOperator_IS_NULL                FilterOperator = 9  // IS NULL
+	FilterOperator_IS_NOT_NULL            FilterOperator = 10 // IS NOT NULL
+	FilterOperator_BETWEEN                FilterOperator = 11 // BETWEEN val1 AND val2
+	FilterOperator_STARTS_WITH            FilterOperator = 12 // LIKE value%
+	FilterOperator_ENDS_WITH              FilterOperator = 13 // LIKE %value
+	FilterOperator_CASE_INSENSITIVE_EQUAL FilterOperator = 14 // LOWER(field) = LOWER(value)
+	FilterOperator_NOT_LIKE               FilterOperator = 15 // NOT LIKE %value%
+	FilterOperator_NOT_BETWEEN            FilterOperator = 16 // NOT BETWEEN val1 AND val2
 )
 
 // Enum value maps for FilterOperator.
@@ -54,20 +59,30 @@ var (
 		9:  "IS_NULL",
 		10: "IS_NOT_NULL",
 		11: "BETWEEN",
+		12: "STARTS_WITH",
+		13: "ENDS_WITH",
+		14: "CASE_INSENSITIVE_EQUAL",
+		15: "NOT_LIKE",
+		16: "NOT_BETWEEN",
 	}
 	FilterOperator_value = map[string]int32{
-		"EQUAL":              0,
-		"NOT_EQUAL":          1,
-		"GREATER_THAN":       2,
-		"GREATER_THAN_EQUAL": 3,
-		"LESS_THAN":          4,
-		"LESS_THAN_EQUAL":    5,
-		"LIKE":               6,
-		"IN":                 7,
-		"NOT_IN":             8,
-		"IS_NULL":            9,
-		"IS_NOT_NULL":        10,
-		"BETWEEN":            11,
+		"EQUAL":                  0,
+		"NOT_EQUAL":              1,
+		"GREATER_THAN":           2,
+		"GREATER_THAN_EQUAL":     3,
+		"LESS_THAN":              4,
+		"LESS_THAN_EQUAL":        5,
+		"LIKE":                   6,
+		"IN":                     7,
+		"NOT_IN":                 8,
+		"IS_NULL":                9,
+		"IS_NOT_NULL":            10,
+		"BETWEEN":                11,
+		"STARTS_WITH":            12,
+		"ENDS_WITH":              13,
+		"CASE_INSENSITIVE_EQUAL": 14,
+		"NOT_LIKE":               15,
+		"NOT_BETWEEN":            16,
 	}
 )
 
@@ -145,6 +160,102 @@ func (LogicalCondition) EnumDescriptor() ([]byte, []int) {
 	return file_proto_common_common_proto_rawDescGZIP(), []int{1}
 }
 
+// ============= Sorting =============
+type SortDirection int32
+
+const (
+	SortDirection_ASC  SortDirection = 0
+	SortDirection_DESC SortDirection = 1
+)
+
+// Enum value maps for SortDirection.
+var (
+	SortDirection_name = map[int32]string{
+		0: "ASC",
+		1: "DESC",
+	}
+	SortDirection_value = map[string]int32{
+		"ASC":  0,
+		"DESC": 1,
+	}
+)
+
+func (x SortDirection) Enum() *SortDirection {
+	p := new(SortDirection)
+	*p = x
+	return p
+}
+
+func (x SortDirection) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SortDirection) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_common_common_proto_enumTypes[2].Descriptor()
+}
+
+func (SortDirection) Type() protoreflect.EnumType {
+	return &file_proto_common_common_proto_enumTypes[2]
+}
+
+func (x SortDirection) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SortDirection.Descriptor instead.
+func (SortDirection) EnumDescriptor() ([]byte, []int) {
+	return file_proto_common_common_proto_rawDescGZIP(), []int{2}
+}
+
+type NullsOrder int32
+
+const (
+	NullsOrder_NULLS_DEFAULT NullsOrder = 0 // database default
+	NullsOrder_NULLS_FIRST   NullsOrder = 1
+	NullsOrder_NULLS_LAST    NullsOrder = 2
+)
+
+// Enum value maps for NullsOrder.
+var (
+	NullsOrder_name = map[int32]string{
+		0: "NULLS_DEFAULT",
+		1: "NULLS_FIRST",
+		2: "NULLS_LAST",
+	}
+	NullsOrder_value = map[string]int32{
+		"NULLS_DEFAULT": 0,
+		"NULLS_FIRST":   1,
+		"NULLS_LAST":    2,
+	}
+)
+
+func (x NullsOrder) Enum() *NullsOrder {
+	p := new(NullsOrder)
+	*p = x
+	return p
+}
+
+func (x NullsOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (NullsOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_common_common_proto_enumTypes[3].Descriptor()
+}
+
+func (NullsOrder) Type() protoreflect.EnumType {
+	return &file_proto_common_common_proto_enumTypes[3]
+}
+
+func (x NullsOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use NullsOrder.Descriptor instead.
+func (NullsOrder) EnumDescriptor() ([]byte, []int) {
+	return file_proto_common_common_proto_rawDescGZIP(), []int{3}
+}
+
 // ============= Filter Criteria (Nested Support) =============
 type FilterCriteria struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -340,20 +451,81 @@ func (x *FilterGroup) GetFilters() []*FilterCriteria {
 	return nil
 }
 
+type SortSpec struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`                                    // field to sort by
+	Direction     SortDirection          `protobuf:"varint,2,opt,name=direction,proto3,enum=common.SortDirection" json:"direction,omitempty"` // sort direction
+	Nulls         NullsOrder             `protobuf:"varint,3,opt,name=nulls,proto3,enum=common.NullsOrder" json:"nulls,omitempty"`            // where NULL values go
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SortSpec) Reset() {
+	*x = SortSpec{}
+	mi := &file_proto_common_common_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SortSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SortSpec) ProtoMessage() {}
+
+func (x *SortSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_common_common_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SortSpec.ProtoReflect.Descriptor instead.
+func (*SortSpec) Descriptor() ([]byte, []int) {
+	return file_proto_common_common_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SortSpec) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *SortSpec) GetDirection() SortDirection {
+	if x != nil {
+		return x.Direction
+	}
+	return SortDirection_ASC
+}
+
+func (x *SortSpec) GetNulls() NullsOrder {
+	if x != nil {
+		return x.Nulls
+	}
+	return NullsOrder_NULLS_DEFAULT
+}
+
 // ============= Pagination =============
 type Pagination struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`                         // page number (starting from 1)
 	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"` // number of items per page
-	SortBy        string                 `protobuf:"bytes,3,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`        // field to sort by
+	SortBy        string                 `protobuf:"bytes,3,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`        // field to sort by (single key, use sort for more)
 	Descending    bool                   `protobuf:"varint,4,opt,name=descending,proto3" json:"descending,omitempty"`             // sort direction (false = ASC, true = DESC)
+	Sort          []*SortSpec            `protobuf:"bytes,5,rep,name=sort,proto3" json:"sort,omitempty"`                          // sort keys in order; id breaks the remaining ties
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Pagination) Reset() {
 	*x = Pagination{}
-	mi := &file_proto_common_common_proto_msgTypes[3]
+	mi := &file_proto_common_common_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -365,7 +537,7 @@ func (x *Pagination) String() string {
 func (*Pagination) ProtoMessage() {}
 
 func (x *Pagination) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_common_common_proto_msgTypes[3]
+	mi := &file_proto_common_common_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -378,7 +550,7 @@ func (x *Pagination) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Pagination.ProtoReflect.Descriptor instead.
 func (*Pagination) Descriptor() ([]byte, []int) {
-	return file_proto_common_common_proto_rawDescGZIP(), []int{3}
+	return file_proto_common_common_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Pagination) GetPage() int32 {
@@ -409,18 +581,27 @@ func (x *Pagination) GetDescending() bool {
 	return false
 }
 
+func (x *Pagination) GetSort() []*SortSpec {
+	if x != nil {
+		return x.Sort
+	}
+	return nil
+}
+
 // ============= Generic Search Request =============
 type SearchRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Pagination    *Pagination            `protobuf:"bytes,1,opt,name=pagination,proto3" json:"pagination,omitempty"`
 	Filters       []*FilterCriteria      `protobuf:"bytes,2,rep,name=filters,proto3" json:"filters,omitempty"`
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`                 // next_page_token of the previous page (page is then ignored)
+	IncludeTotal  *bool                  `protobuf:"varint,4,opt,name=include_total,json=includeTotal,proto3,oneof" json:"include_total,omitempty"` // count the matching rows (default: only without page_token)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SearchRequest) Reset() {
 	*x = SearchRequest{}
-	mi := &file_proto_common_common_proto_msgTypes[4]
+	mi := &file_proto_common_common_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -432,7 +613,7 @@ func (x *SearchRequest) String() string {
 func (*SearchRequest) ProtoMessage() {}
 
 func (x *SearchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_common_common_proto_msgTypes[4]
+	mi := &file_proto_common_common_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -445,7 +626,7 @@ func (x *SearchRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
 func (*SearchRequest) Descriptor() ([]byte, []int) {
-	return file_proto_common_common_proto_rawDescGZIP(), []int{4}
+	return file_proto_common_common_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *SearchRequest) GetPagination() *Pagination {
@@ -462,6 +643,20 @@ func (x *SearchRequest) GetFilters() []*FilterCriteria {
 	return nil
 }
 
+func (x *SearchRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetIncludeTotal() bool {
+	if x != nil && x.IncludeTotal != nil {
+		return *x.IncludeTotal
+	}
+	return false
+}
+
 var File_proto_common_common_proto protoreflect.FileDescriptor
 
 const file_proto_common_common_proto_rawDesc = "" +
@@ -478,7 +673,11 @@ const file_proto_common_common_proto_rawDesc = "" +
 	"\x06values\x18\x03 \x03(\tR\x06values\"o\n" +
 	"\vFilterGroup\x12.\n" +
 	"\x05logic\x18\x01 \x01(\x0e2\x18.common.LogicalConditionR\x05logic\x120\n" +
-	"\afilters\x18\x02 \x03(\v2\x16.common.FilterCriteriaR\afilters\"v\n" +
+	"\afilters\x18\x02 \x03(\v2\x16.common.FilterCriteriaR\afilters\"\x7f\n" +
+	"\bSortSpec\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x123\n" +
+	"\tdirection\x18\x02 \x01(\x0e2\x15.common.SortDirectionR\tdirection\x12(\n" +
+	"\x05nulls\x18\x03 \x01(\x0e2\x12.common.NullsOrderR\x05nulls\"\x9c\x01\n" +
 	"\n" +
 	"Pagination\x12\x12\n" +
 	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
@@ -486,12 +685,17 @@ const file_proto_common_common_proto_rawDesc = "" +
 	"\asort_by\x18\x03 \x01(\tR\x06sortBy\x12\x1e\n" +
 	"\n" +
 	"descending\x18\x04 \x01(\bR\n" +
-	"descending\"u\n" +
+	"descending\x12$\n" +
+	"\x04sort\x18\x05 \x03(\v2\x10.common.SortSpecR\x04sort\"\xd0\x01\n" +
 	"\rSearchRequest\x122\n" +
 	"\n" +
 	"pagination\x18\x01 \x01(\v2\x12.common.PaginationR\n" +
 	"pagination\x120\n" +
-	"\afilters\x18\x02 \x03(\v2\x16.common.FilterCriteriaR\afilters*\xc1\x01\n" +
+	"\afilters\x18\x02 \x03(\v2\x16.common.FilterCriteriaR\afilters\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\x12(\n" +
+	"\rinclude_total\x18\x04 \x01(\bH\x00R\fincludeTotal\x88\x01\x01B\x10\n" +
+	"\x0e_include_total*\x9c\x02\n" +
 	"\x0eFilterOperator\x12\t\n" +
 	"\x05EQUAL\x10\x00\x12\r\n" +
 	"\tNOT_EQUAL\x10\x01\x12\x10\n" +
@@ -506,10 +710,24 @@ const file_proto_common_common_proto_rawDesc = "" +
 	"\aIS_NULL\x10\t\x12\x0f\n" +
 	"\vIS_NOT_NULL\x10\n" +
 	"\x12\v\n" +
-	"\aBETWEEN\x10\v*#\n" +
+	"\aBETWEEN\x10\v\x12\x0f\n" +
+	"\vSTARTS_WITH\x10\f\x12\r\n" +
+	"\tENDS_WITH\x10\r\x12\x1a\n" +
+	"\x16CASE_INSENSITIVE_EQUAL\x10\x0e\x12\f\n" +
+	"\bNOT_LIKE\x10\x0f\x12\x0f\n" +
+	"\vNOT_BETWEEN\x10\x10*#\n" +
 	"\x10LogicalCondition\x12\a\n" +
 	"\x03AND\x10\x00\x12\x06\n" +
-	"\x02OR\x10\x01B\x15Z\x13thaily/proto/commonb\x06proto3"
+	"\x02OR\x10\x01*\"\n" +
+	"\rSortDirection\x12\a\n" +
+	"\x03ASC\x10\x00\x12\b\n" +
+	"\x04DESC\x10\x01*@\n" +
+	"\n" +
+	"NullsOrder\x12\x11\n" +
+	"\rNULLS_DEFAULT\x10\x00\x12\x0f\n" +
+	"\vNULLS_FIRST\x10\x01\x12\x0e\n" +
+	"\n" +
+	"NULLS_LAST\x10\x02B\x15Z\x13thaily/proto/commonb\x06proto3"
 
 var (
 	file_proto_common_common_proto_rawDescOnce sync.Once
@@ -523,30 +741,36 @@ func file_proto_common_common_proto_rawDescGZIP() []byte {
 	return file_proto_common_common_proto_rawDescData
 }
 
-var file_proto_common_common_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_proto_common_common_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_proto_common_common_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_proto_common_common_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_proto_common_common_proto_goTypes = []any{
 	(FilterOperator)(0),     // 0: common.FilterOperator
 	(LogicalCondition)(0),   // 1: common.LogicalCondition
-	(*FilterCriteria)(nil),  // 2: common.FilterCriteria
-	(*FilterCondition)(nil), // 3: common.FilterCondition
-	(*FilterGroup)(nil),     // 4: common.FilterGroup
-	(*Pagination)(nil),      // 5: common.Pagination
-	(*SearchRequest)(nil),   // 6: common.SearchRequest
+	(SortDirection)(0),      // 2: common.SortDirection
+	(NullsOrder)(0),         // 3: common.NullsOrder
+	(*FilterCriteria)(nil),  // 4: common.FilterCriteria
+	(*FilterCondition)(nil), // 5: common.FilterCondition
+	(*FilterGroup)(nil),     // 6: common.FilterGroup
+	(*SortSpec)(nil),        // 7: common.SortSpec
+	(*Pagination)(nil),      // 8: common.Pagination
+	(*SearchRequest)(nil),   // 9: common.SearchRequest
 }
 var file_proto_common_common_proto_depIdxs = []int32{
-	3, // 0: common.FilterCriteria.condition:type_name -> common.FilterCondition
-	4, // 1: common.FilterCriteria.group:type_name -> common.FilterGroup
-	0, // 2: common.FilterCondition.operator:type_name -> common.FilterOperator
-	1, // 3: common.FilterGroup.logic:type_name -> common.LogicalCondition
-	2, // 4: common.FilterGroup.filters:type_name -> common.FilterCriteria
-	5, // 5: common.SearchRequest.pagination:type_name -> common.Pagination
-	2, // 6: common.SearchRequest.filters:type_name -> common.FilterCriteria
-	7, // [7:7] is the sub-list for method output_type
-	7, // [7:7] is the sub-list for method input_type
-	7, // [7:7] is the sub-list for extension type_name
-	7, // [7:7] is the sub-list for extension extendee
-	0, // [0:7] is the sub-list for field type_name
+	5,  // 0: common.FilterCriteria.condition:type_name -> common.FilterCondition
+	6,  // 1: common.FilterCriteria.group:type_name -> common.FilterGroup
+	0,  // 2: common.FilterCondition.operator:type_name -> common.FilterOperator
+	1,  // 3: common.FilterGroup.logic:type_name -> common.LogicalCondition
+	4,  // 4: common.FilterGroup.filters:type_name -> common.FilterCriteria
+	2,  // 5: common.SortSpec.direction:type_name -> common.SortDirection
+	3,  // 6: common.SortSpec.nulls:type_name -> common.NullsOrder
+	7,  // 7: common.Pagination.sort:type_name -> common.SortSpec
+	8,  // 8: common.SearchRequest.pagination:type_name -> common.Pagination
+	4,  // 9: common.SearchRequest.filters:type_name -> common.FilterCriteria
+	10, // [10:10] is the sub-list for method output_type
+	10, // [10:10] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_proto_common_common_proto_init() }
@@ -558,13 +782,14 @@ func file_proto_common_common_proto_init() {
 		(*FilterCriteria_Condition)(nil),
 		(*FilterCriteria_Group)(nil),
 	}
+	file_proto_common_common_proto_msgTypes[5].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_common_common_proto_rawDesc), len(file_proto_common_common_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   5,
+			NumEnums:      4,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
 		},